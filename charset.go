@@ -0,0 +1,41 @@
+package scraper
+
+import (
+	"bytes"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+)
+
+// utf8BOM is the byte order mark of UTF-8 encoded data
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// DecodeHTML transcodes the HTML data body to UTF-8, detecting its character set from a byte order mark,
+// contentType (the value of a Content-Type header, may be empty) or a <meta charset> element, falling back
+// to UTF-8 for valid UTF-8 and windows-1252 otherwise, charsetName (e.g. "shift_jis") overrides the detection
+func DecodeHTML(body []byte, contentType, charsetName string) ([]byte, error) {
+	var enc encoding.Encoding
+	var name string
+	if charsetName != "" {
+		if enc, name = charset.Lookup(charsetName); enc == nil {
+			return nil, newErr(ErrInvalidConfig, "unknown charset "+charsetName)
+		}
+	} else {
+		enc, name, _ = charset.DetermineEncoding(body, contentType)
+	}
+
+	if name != "utf-8" {
+		var err error
+		if body, err = enc.NewDecoder().Bytes(body); err != nil {
+			return nil, err
+		}
+	}
+	// a byte order mark is kept by some decoders
+	return bytes.TrimPrefix(body, utf8BOM), nil
+}
+
+// validCharset reports whether the charset name is known
+func validCharset(name string) bool {
+	enc, _ := charset.Lookup(name)
+	return enc != nil
+}
@@ -0,0 +1,88 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// encode encodes the UTF-8 str using enc
+func encode(t *testing.T, enc encoding.Encoding, str string) []byte {
+	data, err := enc.NewEncoder().Bytes([]byte(str))
+	require.NoError(t, err)
+	return data
+}
+
+func TestDecodeHTML(t *testing.T) {
+	testCases := []struct {
+		name        string
+		body        []byte
+		contentType string
+		charset     string
+		result      string
+	}{
+		{"utf8", []byte("<p>Grüße</p>"), "", "", "<p>Grüße</p>"},
+		{"utf8BOM", append([]byte{0xEF, 0xBB, 0xBF}, "<p>Grüße</p>"...), "", "", "<p>Grüße</p>"},
+		{"utf16BOM", encode(t, unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), "<p>Grüße</p>"), "", "", "<p>Grüße</p>"},
+		{"contentType", encode(t, japanese.ShiftJIS, "<p>日本語</p>"), "text/html; charset=Shift_JIS", "", "<p>日本語</p>"},
+		{"metaCharset", encode(t, charmap.Windows1252, `<meta charset="windows-1252"><p>Café €</p>`), "text/html", "", `<meta charset="windows-1252"><p>Café €</p>`},
+		{"metaHTTPEquiv", encode(t, japanese.ShiftJIS, `<meta http-equiv="Content-Type" content="text/html; charset=shift_jis"><p>日本語</p>`), "", "",
+			`<meta http-equiv="Content-Type" content="text/html; charset=shift_jis"><p>日本語</p>`},
+		{"fallbackWindows1252", encode(t, charmap.Windows1252, "<p>Café</p>"), "", "", "<p>Café</p>"},
+		{"override", encode(t, japanese.ShiftJIS, "<p>日本語</p>"), "text/html; charset=utf-8", "shift_jis", "<p>日本語</p>"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			body, err := DecodeHTML(tc.body, tc.contentType, tc.charset)
+			require.NoError(t, err)
+			assert.Equal(t, tc.result, string(body))
+		})
+	}
+
+	_, err := DecodeHTML([]byte("<p></p>"), "", "klingon")
+	require.Error(t, err)
+	assert.Equal(t, ErrInvalidConfig, int(err.(Error).ErrType))
+}
+
+func TestScrapeCharset(t *testing.T) {
+	body := encode(t, japanese.ShiftJIS, "<h1>日本語</h1>")
+	fetcher := FetcherFunc(func(ctx context.Context, URL string) (*Response, error) {
+		return &Response{URL: URL, StatusCode: http.StatusOK, Header: http.Header{"Content-Type": {"text/html; charset=shift_jis"}}, Body: body}, nil
+	})
+	testWebsite := Website{
+		URL:      "https://example.jp",
+		Fetcher:  fetcher,
+		Elements: []Element{{HtmlElement: HtmlElement{Typ: "h1"}}},
+	}
+
+	content, err := testWebsite.Scrape(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "日本語", content)
+
+	testWebsite.Charset = "shift_jis"
+	res, err := testWebsite.ScrapeHTML(string(body))
+	require.NoError(t, err)
+	assert.Equal(t, "日本語", res.String())
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "page.html")
+	require.NoError(t, os.WriteFile(path, encode(t, charmap.Windows1252, `<meta charset="windows-1252"><h1>Café</h1>`), 0o644))
+	fileWebsite := Website{URL: "file://" + filepath.ToSlash(path), Elements: testWebsite.Elements, Fetcher: FileFetcher{}}
+	content, err = fileWebsite.Scrape(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Café", content)
+
+	testWebsite.Charset = "klingon"
+	assert.Error(t, testWebsite.Validate())
+}
@@ -0,0 +1,241 @@
+// Command goscraper scrapes the websites defined inside of JSON or YAML files (see scraper.LoadWebsites)
+// and writes the results to stdout or a file.
+//
+// Usage:
+//
+//	goscraper [flags] <website file>...
+//	goscraper probe [probe flags] [html file]
+//
+// The flags are:
+//
+//	-format text|json|ndjson|csv
+//		the output format, defaults to text
+//	-o path
+//		writes the output to path instead of stdout
+//	-var name=value
+//		replaces {{name}} inside of the websites (e.g. their URL) with value, may be repeated
+//	-timeout duration
+//		aborts the scrape after duration, e.g. 30s
+//	-fixtures dir
+//		replays the responses from the fixtures inside of dir instead of fetching them,
+//		or records them there if GOSCRAPER_RECORD is set (see scraper.FixtureFetcher)
+//	-rate n, -burst n, -delay duration
+//		limits the requests to every host to n per second (with bursts of n requests)
+//		and waits at least duration between two requests (see scraper.PoliteFetcher)
+//	-robots, -user-agent name
+//		honors the robots.txt of every host, identifying as the user agent name, defaults to goScraper
+//	-session path
+//		shares the cookies between all requests, loading them from and saving them to path (see scraper.Session)
+//
+// The probe subcommand prints every node of a local HTML file (or stdin) matching an element,
+// see "goscraper probe -h" for its flags.
+//
+// The exit code is 0 on success, 1 on an unexpected error and 2 on invalid usage. Errors of the scraper
+// exit with 10 plus their scraper.ErrType:
+//
+//	10 missing element
+//	11 no node found
+//	12 index out of range
+//	13 HTTP status
+//	14 invalid selector
+//	15 invalid XPath
+//	16 missing attribute
+//	17 invalid index
+//	18 unmarshal
+//	19 invalid website definition
+//	20 missing fixture
+//	21 disallowed URL (by robots.txt or a local file followed from a remote page)
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	scraper "github.com/keinberger/goScraper"
+)
+
+const (
+	exitOK = iota
+	exitErr
+	exitUsage
+	// exitErrType is added to the scraper.ErrType of an error
+	exitErrType = 10
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// vars defines the data structure for the -var flags, mapping the name of a variable to its value
+type vars map[string]string
+
+// String returns the variables of v as a flag value
+func (v vars) String() string {
+	var pairs []string
+	for name, value := range v {
+		pairs = append(pairs, name+"="+value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Set adds the variable of the flag value "name=value" to v
+func (v vars) Set(str string) error {
+	name, value, ok := strings.Cut(str, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("invalid variable %q, expected name=value", str)
+	}
+	v[name] = value
+	return nil
+}
+
+// funcs returns the replacement funcs of the variables of v, as used by Website.Scrape
+func (v vars) funcs() map[string]interface{} {
+	funcs := make(map[string]interface{}, len(v))
+	for name, value := range v {
+		placeholder, value := "{{"+name+"}}", value
+		funcs[placeholder] = func(str string) string {
+			return strings.ReplaceAll(str, placeholder, value)
+		}
+	}
+	return funcs
+}
+
+// run runs goscraper with the command-line arguments args, returning the exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) > 0 && args[0] == "probe" {
+		return probe(args[1:], stdin, stdout, stderr)
+	}
+
+	variables := vars{}
+	fs := flag.NewFlagSet("goscraper", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "text", "output format: text, json, ndjson or csv")
+	out := fs.String("o", "", "write the output to `path` instead of stdout")
+	timeout := fs.Duration("timeout", 0, "abort the scrape after `duration`")
+	fixtures := fs.String("fixtures", "", "replay (or record, if "+scraper.RecordEnv+" is set) the responses using the fixtures inside of `dir`")
+	var politeness scraper.Politeness
+	fs.Float64Var(&politeness.RequestsPerSecond, "rate", 0, "limit the requests to every host to `n` per second")
+	fs.IntVar(&politeness.Burst, "burst", 0, "allow bursts of `n` requests to every host when using -rate")
+	fs.DurationVar(&politeness.MinDelay, "delay", 0, "wait at least `duration` between two requests to the same host")
+	fs.BoolVar(&politeness.Robots, "robots", false, "honor the robots.txt of every host")
+	fs.StringVar(&politeness.UserAgent, "user-agent", "", "send and match the robots.txt groups of the user agent `name` (default \""+scraper.DefaultUserAgent+"\")")
+	sessionPath := fs.String("session", "", "share the cookies between all requests, persisting them to `path`")
+	fs.Var(variables, "var", "replace {{name}} inside of the websites with value, given as `name=value`")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: goscraper [flags] <website file>...")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	w, ok := writers[*format]
+	if !ok {
+		fmt.Fprintf(stderr, "goscraper: unknown format %q\n", *format)
+		return exitUsage
+	}
+
+	var websites []scraper.Website
+	for _, path := range fs.Args() {
+		ws, err := scraper.LoadWebsites(path)
+		if err != nil {
+			return fail(stderr, err)
+		}
+		for _, website := range ws {
+			if err := website.Validate(); err != nil {
+				return fail(stderr, fmt.Errorf("%s: %w", path, err))
+			}
+		}
+		websites = append(websites, ws...)
+	}
+	var fetcher scraper.Fetcher
+	if *fixtures != "" {
+		fetcher = scraper.NewFixtureFetcher(*fixtures, nil)
+	}
+	var session *scraper.Session
+	if *sessionPath != "" {
+		var err error
+		if session, err = scraper.NewSession(*sessionPath); err != nil {
+			return fail(stderr, err)
+		}
+		session.Fetcher = fetcher
+		fetcher = session
+	}
+	if politeness != (scraper.Politeness{}) {
+		fetcher = scraper.NewPoliteFetcher(fetcher, politeness)
+	}
+	if fetcher != nil {
+		for k := range websites {
+			websites[k].Fetcher = fetcher
+		}
+	}
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	var firstErr error
+	funcs := variables.funcs()
+	results := make([]*scraper.Result, 0, len(websites))
+	for _, website := range websites {
+		res, err := website.ScrapeResultContext(ctx, &funcs)
+		if err == nil {
+			err = res.Err()
+			results = append(results, res)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "goscraper: %s: %v\n", website.URL, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if session != nil {
+		if err := session.Save(); err != nil {
+			return fail(stderr, err)
+		}
+	}
+
+	dst := stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return fail(stderr, err)
+		}
+		defer f.Close()
+		dst = f
+	}
+	if err := w(dst, results); err != nil {
+		return fail(stderr, err)
+	}
+	return exitCode(firstErr)
+}
+
+// fail prints err and returns its exit code
+func fail(stderr io.Writer, err error) int {
+	fmt.Fprintf(stderr, "goscraper: %v\n", err)
+	return exitCode(err)
+}
+
+// exitCode returns the exit code for err
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	if typ, ok := scraper.ErrTypeOf(err); ok {
+		return exitErrType + int(typ)
+	}
+	return exitErr
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	scraper "github.com/keinberger/goScraper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/shop" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `<h1>Shop</h1><ul><li>Apple</li><li>Pear</li></ul>`)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	config := filepath.Join(dir, "shop.yaml")
+	require.NoError(t, os.WriteFile(config, []byte(`
+url: `+server.URL+`/{{page}}
+separator: " | "
+elements:
+  - name: title
+    htmlElement: {typ: h1}
+  - name: fruit
+    htmlElement: {typ: li}
+    all: true
+`), 0o644))
+
+	testCases := []struct {
+		name   string
+		args   []string
+		code   int
+		output string
+	}{
+		{"text", []string{"-var", "page=shop", config}, exitOK, "Shop | Apple | Pear\n"},
+		{"csv", []string{"-format", "csv", "-var", "page=shop", config}, exitOK,
+			"url,name,value,error\n" + server.URL + "/shop,title,Shop,\n" + server.URL + "/shop,fruit,Apple,\n" + server.URL + "/shop,fruit,Pear,\n"},
+		{"ndjson", []string{"-format", "ndjson", "-var", "page=shop", config}, exitOK,
+			`{"url":"` + server.URL + `/shop","attempts":1,"elements":[{"name":"title","value":"Shop","url":"` + server.URL + `/shop","matches":1},` +
+				`{"name":"fruit","value":"Apple | Pear","url":"` + server.URL + `/shop","matches":2,"items":[` +
+				`{"value":"Apple","url":"` + server.URL + `/shop"},{"value":"Pear","url":"` + server.URL + `/shop"}]}]}` + "\n"},
+		{"httpStatus", []string{"-var", "page=missing", config}, exitErrType + scraper.ErrHTTPStatus, ""},
+		{"missingFile", []string{filepath.Join(dir, "missing.yaml")}, exitErr, ""},
+		{"unknownFormat", []string{"-format", "xml", config}, exitUsage, ""},
+		{"noFiles", nil, exitUsage, ""},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tc.args, nil, &stdout, &stderr)
+			assert.Equal(t, tc.code, code, stderr.String())
+			assert.Equal(t, tc.output, stdout.String())
+		})
+	}
+}
+
+func TestRunOutputFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<h1>Shop</h1>`)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	config := filepath.Join(dir, "shop.json")
+	require.NoError(t, os.WriteFile(config, []byte(`{"url": "`+server.URL+`", "elements": [{"htmlElement": {"typ": "h1"}}]}`), 0o644))
+
+	var stdout, stderr bytes.Buffer
+	out := filepath.Join(dir, "out.txt")
+	require.Equal(t, exitOK, run([]string{"-o", out, config}, nil, &stdout, &stderr), stderr.String())
+	assert.Empty(t, stdout.String())
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "Shop\n", string(data))
+}
+
+func TestRunInvalidWebsite(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(config, []byte(`{"elements": [{"htmlElement": {}}]}`), 0o644))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{config}, nil, &stdout, &stderr)
+	assert.Equal(t, exitErrType+scraper.ErrInvalidConfig, code)
+	assert.Contains(t, stderr.String(), "Elements[0]: missing typ, selector or xpath")
+}
+
+func TestRunFixtures(t *testing.T) {
+	t.Setenv(scraper.RecordEnv, "")
+	dir := t.TempDir()
+	config := filepath.Join(dir, "shop.json")
+	require.NoError(t, os.WriteFile(config, []byte(`{"url": "https://example.com", "elements": [{"htmlElement": {"typ": "h1"}}]}`), 0o644))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-fixtures", filepath.Join(dir, "fixtures"), config}, nil, &stdout, &stderr)
+	assert.Equal(t, exitErrType+scraper.ErrMissingFixture, code)
+	assert.Contains(t, stderr.String(), "no fixture for GET https://example.com")
+}
+
+func TestRunSession(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		visits := 0
+		if c, err := r.Cookie("visits"); err == nil {
+			fmt.Sscan(c.Value, &visits)
+		}
+		visits++
+		http.SetCookie(w, &http.Cookie{Name: "visits", Value: fmt.Sprint(visits), Path: "/", MaxAge: 3600})
+		fmt.Fprintf(w, "<h1>Visit %d</h1>", visits)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	config := filepath.Join(dir, "shop.json")
+	require.NoError(t, os.WriteFile(config, []byte(`{"url": "`+server.URL+`", "elements": [{"htmlElement": {"typ": "h1"}}]}`), 0o644))
+
+	// the cookies of the first run are sent by the second one
+	session := filepath.Join(dir, "session.json")
+	for _, want := range []string{"Visit 1\n", "Visit 2\n"} {
+		var stdout, stderr bytes.Buffer
+		require.Equal(t, exitOK, run([]string{"-session", session, config}, nil, &stdout, &stderr), stderr.String())
+		assert.Equal(t, want, stdout.String())
+	}
+}
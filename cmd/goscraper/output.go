@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	scraper "github.com/keinberger/goScraper"
+)
+
+// writers contains the func writing the results in each output format, keyed by the name of the format
+var writers = map[string]func(io.Writer, []*scraper.Result) error{
+	"text":   writeText,
+	"json":   writeJSON,
+	"ndjson": writeNDJSON,
+	"csv":    writeCSV,
+}
+
+// writeText writes the String of every result on its own line
+func writeText(w io.Writer, results []*scraper.Result) error {
+	for _, res := range results {
+		if _, err := fmt.Fprintln(w, res.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeJSON writes all results as a single JSON array
+func writeJSON(w io.Writer, results []*scraper.Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+// writeNDJSON writes every result as JSON on its own line
+func writeNDJSON(w io.Writer, results []*scraper.Result) error {
+	enc := json.NewEncoder(w)
+	for _, res := range results {
+		if err := enc.Encode(res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCSV writes a row for every element of all results, or for every item of elements scraping multiple nodes
+func writeCSV(w io.Writer, results []*scraper.Result) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"url", "name", "value", "error"}); err != nil {
+		return err
+	}
+	for _, res := range results {
+		for _, el := range res.Elements {
+			rows := []scraper.ElementResult{el}
+			if el.Items != nil {
+				rows = el.Items
+			}
+			for _, row := range rows {
+				var msg string
+				if row.Err != nil {
+					msg = row.Err.Error()
+				}
+				if err := cw.Write([]string{res.URL, el.Name, row.Value, msg}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
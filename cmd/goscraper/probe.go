@@ -0,0 +1,138 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	scraper "github.com/keinberger/goScraper"
+)
+
+// maxSnippetLen is the maximum number of runes of the rendered node printed by probe
+const maxSnippetLen = 120
+
+// tags defines the data structure for the -tag flags of probe
+type tags []scraper.Tag
+
+// String returns the tags of t as a flag value
+func (t *tags) String() string {
+	var pairs []string
+	for _, tag := range *t {
+		pairs = append(pairs, tag.Typ+"="+tag.Value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Set adds the tag of the flag value "typ=value" to t
+func (t *tags) Set(str string) error {
+	typ, value, ok := strings.Cut(str, "=")
+	if !ok || typ == "" {
+		return fmt.Errorf("invalid tag %q, expected typ=value", str)
+	}
+	*t = append(*t, scraper.Tag{Typ: typ, Value: value})
+	return nil
+}
+
+// probe runs the probe subcommand with the command-line arguments args, returning the exit code
+func probe(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var tagFlags tags
+	fs := flag.NewFlagSet("goscraper probe", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	spec := fs.String("element", "", "the element as inline JSON or YAML, e.g. `{htmlElement: {typ: a}}`")
+	selector := fs.String("selector", "", "match the CSS `selector`")
+	xpath := fs.String("xpath", "", "match the XPath `expression`")
+	typ := fs.String("typ", "", "match elements of the `tag` name")
+	fs.Var(&tagFlags, "tag", "match elements having the attribute, given as `typ=value`, may be repeated")
+	attr := fs.String("attr", "", "print the value of the `attribute` instead of the text")
+	charsetName := fs.String("charset", "", "override the detected `charset` of the HTML data")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: goscraper probe [flags] [html file]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() > 1 {
+		fmt.Fprintf(stderr, "goscraper: probe expects at most one html file, got %d\n", fs.NArg())
+		fs.Usage()
+		return exitUsage
+	}
+
+	var el scraper.Element
+	if *spec != "" {
+		var err error
+		if el, err = scraper.ParseElement(strings.NewReader(*spec)); err != nil {
+			fmt.Fprintf(stderr, "goscraper: invalid element: %v\n", err)
+			return exitUsage
+		}
+	}
+	if *selector != "" {
+		el.Selector = *selector
+	}
+	if *xpath != "" {
+		el.XPath = *xpath
+	}
+	if *typ != "" {
+		el.Typ = *typ
+	}
+	if len(tagFlags) > 0 {
+		el.Tags = tagFlags
+	}
+	if *attr != "" {
+		el.Attribute = *attr
+	}
+	if el.Selector == "" && el.XPath == "" && el.Typ == "" {
+		fmt.Fprintln(stderr, "goscraper: missing -element, -selector, -xpath or -typ")
+		return exitUsage
+	}
+
+	src := stdin
+	if path := fs.Arg(0); path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return fail(stderr, err)
+		}
+		defer f.Close()
+		src = f
+	}
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return fail(stderr, err)
+	}
+	if data, err = scraper.DecodeHTML(data, "", *charsetName); err != nil {
+		return fail(stderr, err)
+	}
+	nodeTree, err := scraper.GetHTMLNode(string(data))
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	nodes, err := el.GetElementNodes(nodeTree)
+	if err != nil {
+		return fail(stderr, err)
+	}
+	for k, node := range nodes {
+		fmt.Fprintf(stdout, "[%d] %s\n", k, scraper.NodePath(node))
+		fmt.Fprintf(stdout, "    %s\n", snippet(scraper.RenderNode(node)))
+		if content, err := el.ContentOfNode(node); err != nil {
+			fmt.Fprintf(stdout, "    error: %v\n", err)
+		} else {
+			fmt.Fprintf(stdout, "    %s\n", strconv.Quote(content))
+		}
+	}
+	fmt.Fprintf(stdout, "%d matching nodes\n", len(nodes))
+	return exitOK
+}
+
+// snippet collapses the whitespace of the rendered node str and shortens it to maxSnippetLen runes
+func snippet(str string) string {
+	str = strings.Join(strings.Fields(str), " ")
+	if runes := []rune(str); len(runes) > maxSnippetLen {
+		return string(runes[:maxSnippetLen]) + "…"
+	}
+	return str
+}
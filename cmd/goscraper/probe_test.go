@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	scraper "github.com/keinberger/goScraper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testProbeHTML = `<ul>
+	<li><a href="/apple">Apple</a></li>
+	<li><a>Pear</a></li>
+</ul>`
+
+func TestProbe(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "page.html")
+	require.NoError(t, os.WriteFile(file, []byte(testProbeHTML), 0o644))
+
+	testCases := []struct {
+		name   string
+		args   []string
+		stdin  string
+		code   int
+		output string
+	}{
+		{"selector", []string{"probe", "-selector", "li", file}, "", exitOK, `[0] html > body > ul > li:nth-of-type(1)
+    <li><a href="/apple">Apple</a></li>
+    "Apple"
+[1] html > body > ul > li:nth-of-type(2)
+    <li><a>Pear</a></li>
+    "Pear"
+2 matching nodes
+`},
+		{"elementFromStdin", []string{"probe", "-element", "{htmlElement: {typ: a}, settings: {attribute: href, formatting: {addBefore: 'https://example.com'}}}"}, testProbeHTML, exitOK, `[0] html > body > ul > li:nth-of-type(1) > a
+    <a href="/apple">Apple</a>
+    "https://example.com/apple"
+[1] html > body > ul > li:nth-of-type(2) > a
+    <a>Pear</a>
+    error: missing attribute href of a
+2 matching nodes
+`},
+		{"noMatch", []string{"probe", "-xpath", "//table", file}, "", exitErrType + scraper.ErrMissingElement, ""},
+		{"invalidSelector", []string{"probe", "-selector", "li >", file}, "", exitErrType + scraper.ErrInvalidSelector, ""},
+		{"missingElement", []string{"probe", file}, "", exitUsage, ""},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tc.args, strings.NewReader(tc.stdin), &stdout, &stderr)
+			assert.Equal(t, tc.code, code, stderr.String())
+			assert.Equal(t, tc.output, stdout.String())
+		})
+	}
+}
+
+func TestProbeUsageErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		args   []string
+		stderr string
+	}{
+		{"unknownElementField", []string{"probe", "-element", "{htmlElemnt: {typ: a}}"}, `goscraper: invalid element: input:1:2: unknown field "htmlElemnt" of Element`},
+		{"multipleFiles", []string{"probe", "-selector", "li", "a.html", "b.html"}, "goscraper: probe expects at most one html file, got 2\nusage: goscraper probe"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tc.args, strings.NewReader(testProbeHTML), &stdout, &stderr)
+			assert.Equal(t, exitUsage, code)
+			assert.Contains(t, stderr.String(), tc.stderr)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
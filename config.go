@@ -0,0 +1,258 @@
+package scraper
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// includeKey is the key of a website definition including the websites of another file
+const includeKey = "include"
+
+var (
+	websiteType = reflect.TypeOf(Website{})
+	elementType = reflect.TypeOf(Element{})
+)
+
+// LoadWebsite loads the website defined inside of the JSON or YAML file at path,
+// failing if the file does not define exactly one website
+func LoadWebsite(path string) (Website, error) {
+	websites, err := LoadWebsites(path)
+	if err != nil {
+		return Website{}, err
+	}
+	return singleWebsite(websites, path)
+}
+
+// LoadWebsites loads all websites defined inside of the JSON or YAML file at path
+//
+// A file defines a single website, a list of websites or multiple YAML documents each defining websites.
+// Instead of a website, a list may contain the definition {"include": "<path>"}, which will be replaced by the
+// websites of the included file, relative paths being resolved against the directory of the including file.
+// Fields, which are not part of a Website, are reported as an error including their position inside of the file.
+func LoadWebsites(path string) ([]Website, error) {
+	return (&loader{}).loadFile(path)
+}
+
+// ParseWebsite parses the website defined inside of the JSON or YAML data of r like LoadWebsite
+func ParseWebsite(r io.Reader) (Website, error) {
+	websites, err := ParseWebsites(r)
+	if err != nil {
+		return Website{}, err
+	}
+	return singleWebsite(websites, "input")
+}
+
+// ParseWebsites parses all websites defined inside of the JSON or YAML data of r like LoadWebsites,
+// included files are resolved against the working directory
+func ParseWebsites(r io.Reader) ([]Website, error) {
+	return (&loader{}).parse(r, "input", "")
+}
+
+// ParseElement parses the element defined inside of the JSON or YAML data of r, reporting unknown fields
+// like LoadWebsites, e.g. for testing an element on its own
+func ParseElement(r io.Reader) (Element, error) {
+	var el Element
+	var doc yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&doc); errors.Is(err, io.EOF) {
+		return el, newErr(ErrInvalidConfig, "input: missing element")
+	} else if err != nil {
+		return el, configErr("input", err)
+	}
+
+	if err := checkFields(doc.Content[0], elementType, "input"); err != nil {
+		return el, err
+	}
+	if err := doc.Content[0].Decode(&el); err != nil {
+		return el, configErr("input", err)
+	}
+	return el, nil
+}
+
+// singleWebsite returns the only website of websites, defined inside of the file name
+func singleWebsite(websites []Website, name string) (Website, error) {
+	if len(websites) != 1 {
+		return Website{}, newErr(ErrInvalidConfig, name+": expected 1 website, found "+strconv.Itoa(len(websites)))
+	}
+	return websites[0], nil
+}
+
+// loader defines the state of loading website definitions
+type loader struct {
+	// files is the chain of files currently being loaded, used for detecting include cycles
+	files []string
+}
+
+// loadFile loads all websites defined inside of the file at path
+func (l *loader) loadFile(path string) ([]Website, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range l.files {
+		if file == abs {
+			return nil, newErr(ErrInvalidConfig, "include cycle: "+strings.Join(append(l.files, abs), " -> "))
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	l.files = append(l.files, abs)
+	defer func() { l.files = l.files[:len(l.files)-1] }()
+	return l.parse(f, path, filepath.Dir(path))
+}
+
+// parse parses all websites defined inside of r, which has been read from the file name inside of dir
+func (l *loader) parse(r io.Reader, name, dir string) ([]Website, error) {
+	var websites []Website
+
+	dec := yaml.NewDecoder(r)
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, configErr(name, err)
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+
+		nodes := doc.Content
+		if doc.Content[0].Kind == yaml.SequenceNode {
+			nodes = doc.Content[0].Content
+		}
+		for _, node := range nodes {
+			ws, err := l.website(node, name, dir)
+			if err != nil {
+				return nil, err
+			}
+			websites = append(websites, ws...)
+		}
+	}
+	return websites, nil
+}
+
+// website decodes the website defined by node, or the websites of the file it includes
+func (l *loader) website(node *yaml.Node, name, dir string) ([]Website, error) {
+	if node.Kind == yaml.MappingNode && len(node.Content) == 2 && node.Content[0].Value == includeKey {
+		path := node.Content[1].Value
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		return l.loadFile(path)
+	}
+
+	if err := checkFields(node, websiteType, name); err != nil {
+		return nil, err
+	}
+	var w Website
+	if err := node.Decode(&w); err != nil {
+		return nil, configErr(name, err)
+	}
+	return []Website{w}, nil
+}
+
+// checkFields returns an error for the first key of node, which is not a field of the type t
+func checkFields(node *yaml.Node, t reflect.Type, name string) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	switch {
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Tag == "!!merge" {
+				if err := checkFields(value, t, name); err != nil {
+					return err
+				}
+				continue
+			}
+
+			field, fieldName, ok := yamlField(t, key.Value)
+			if !ok {
+				return newErr(ErrInvalidConfig, name+":"+strconv.Itoa(key.Line)+":"+strconv.Itoa(key.Column)+
+					": unknown field "+strconv.Quote(key.Value)+" of "+t.Name())
+			}
+			// the key is decoded using the exact name of the field
+			key.Value = fieldName
+			if err := checkFields(value, field.Type, name); err != nil {
+				return err
+			}
+		}
+	case node.Kind == yaml.SequenceNode:
+		elem := t
+		if t.Kind() == reflect.Slice {
+			elem = t.Elem()
+		} else if t.Kind() != reflect.Struct {
+			// let Decode report the mismatch
+			return nil
+		}
+		for _, item := range node.Content {
+			if err := checkFields(item, elem, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// yamlField returns the field of the struct type t having the YAML key key together with its exact name,
+// preferring an exact match but matching case-insensitively like encoding/json otherwise, so definitions
+// using keys like "URL" and "Elements" stay valid
+func yamlField(t reflect.Type, key string) (reflect.StructField, string, bool) {
+	var folded reflect.StructField
+	var foldedName string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fieldName, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if fieldName == "" {
+			fieldName = strings.ToLower(f.Name)
+		}
+		if fieldName == "-" || f.PkgPath != "" {
+			continue
+		}
+		if fieldName == key {
+			return f, fieldName, true
+		}
+		if foldedName == "" && strings.EqualFold(fieldName, key) {
+			folded, foldedName = f, fieldName
+		}
+	}
+	return folded, foldedName, foldedName != ""
+}
+
+// configErr converts the YAML error err of the file name into an error of type ErrInvalidConfig,
+// rewriting "line N: msg" into "name:N: msg"
+func configErr(name string, err error) error {
+	var msgs []string
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		msgs = typeErr.Errors
+	} else {
+		msgs = []string{strings.TrimPrefix(err.Error(), "yaml: ")}
+	}
+
+	for k, msg := range msgs {
+		if strings.HasPrefix(msg, "line ") {
+			msgs[k] = name + ":" + strings.TrimPrefix(msg, "line ")
+		} else {
+			msgs[k] = name + ": " + msg
+		}
+	}
+	return newErr(ErrInvalidConfig, strings.Join(msgs, "; "))
+}
@@ -0,0 +1,163 @@
+package scraper
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfigFiles writes the files of the map, keyed by name, into a temporary directory and returns its path
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, data := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644))
+	}
+	return dir
+}
+
+func TestLoadWebsite(t *testing.T) {
+	testMap := make(map[string]func(t *testing.T), 0)
+
+	expected := Website{
+		URL:       "https://example.com",
+		Separator: ", ",
+		Elements: []Element{
+			{
+				Name: "title",
+				HtmlElement: HtmlElement{
+					Typ:  "h1",
+					Tags: []Tag{{Typ: "class", Value: "title", Match: MatchToken}},
+				},
+				Index: Last,
+				ContentIsFollowURL: &Website{
+					Elements: []Element{{HtmlElement: HtmlElement{Selector: "p"}}},
+				},
+			},
+		},
+	}
+
+	testMap["yaml"] = func(t *testing.T) {
+		dir := writeConfigFiles(t, map[string]string{"website.yaml": `
+url: https://example.com
+separator: ", "
+elements:
+  - name: title
+    htmlElement:
+      typ: h1
+      tags:
+        - {typ: class, value: title, match: token}
+    index: last
+    followURL:
+      elements:
+        - htmlElement: {selector: p}
+`})
+		w, err := LoadWebsite(filepath.Join(dir, "website.yaml"))
+		require.NoError(t, err)
+		assert.Equal(t, expected, w)
+	}
+	testMap["json"] = func(t *testing.T) {
+		dir := writeConfigFiles(t, map[string]string{"website.json": `{
+	"url": "https://example.com",
+	"separator": ", ",
+	"elements": [
+		{
+			"name": "title",
+			"htmlElement": {"typ": "h1", "tags": [{"typ": "class", "value": "title", "match": "token"}]},
+			"index": "last",
+			"followURL": {"elements": [{"htmlElement": {"selector": "p"}}]}
+		}
+	]
+}`})
+		w, err := LoadWebsite(filepath.Join(dir, "website.json"))
+		require.NoError(t, err)
+		assert.Equal(t, expected, w)
+	}
+	testMap["baselineKeys"] = func(t *testing.T) {
+		// definitions written before the keys were lowercased
+		w, err := ParseWebsite(strings.NewReader(`{
+	"URL": "https://example.com",
+	"separator": ", ",
+	"Elements": [
+		{
+			"name": "title",
+			"htmlElement": {"typ": "h1", "tags": [{"typ": "class", "value": "title", "match": "token"}]},
+			"index": "last",
+			"followURL": {"Elements": [{"HtmlElement": {"Selector": "p"}}]}
+		}
+	]
+}`))
+		require.NoError(t, err)
+		assert.Equal(t, expected, w)
+	}
+	testMap["multipleWebsites"] = func(t *testing.T) {
+		websites, err := ParseWebsites(strings.NewReader(`
+- url: https://example.com/a
+- url: https://example.com/b
+---
+url: https://example.com/c
+`))
+		require.NoError(t, err)
+		require.Len(t, websites, 3)
+		assert.Equal(t, "https://example.com/c", websites[2].URL)
+
+		_, err = ParseWebsite(strings.NewReader(`[{"url": "a"}, {"url": "b"}]`))
+		assert.EqualError(t, err, "input: expected 1 website, found 2")
+	}
+	testMap["includes"] = func(t *testing.T) {
+		dir := writeConfigFiles(t, map[string]string{
+			"all.yaml":  "- include: shop.yaml\n- url: https://example.com/b\n",
+			"shop.yaml": "url: https://example.com/a\n",
+			"a.yaml":    "- include: b.yaml\n",
+			"b.yaml":    "- include: a.yaml\n",
+		})
+
+		websites, err := LoadWebsites(filepath.Join(dir, "all.yaml"))
+		require.NoError(t, err)
+		require.Len(t, websites, 2)
+		assert.Equal(t, "https://example.com/a", websites[0].URL)
+		assert.Equal(t, "https://example.com/b", websites[1].URL)
+
+		_, err = LoadWebsites(filepath.Join(dir, "a.yaml"))
+		require.Error(t, err)
+		assert.Equal(t, ErrInvalidConfig, int(err.(Error).ErrType))
+		assert.Contains(t, err.Error(), "include cycle")
+	}
+	testMap["unknownField"] = func(t *testing.T) {
+		dir := writeConfigFiles(t, map[string]string{"website.yaml": `
+url: https://example.com
+elements:
+  - htmlElement:
+      typ: h1
+      selektor: p
+`})
+		path := filepath.Join(dir, "website.yaml")
+		_, err := LoadWebsite(path)
+		require.Error(t, err)
+		assert.Equal(t, ErrInvalidConfig, int(err.(Error).ErrType))
+		assert.Equal(t, path+`:6:7: unknown field "selektor" of HtmlElement`, err.Error())
+	}
+	testMap["invalidValue"] = func(t *testing.T) {
+		_, err := ParseWebsite(strings.NewReader("url: a\nelements:\n  - index: second\n"))
+		require.Error(t, err)
+		assert.Equal(t, `input:3: invalid index "second"`, err.Error())
+	}
+
+	for testName, testFunc := range testMap {
+		t.Run(testName, testFunc)
+	}
+}
+
+func TestParseElement(t *testing.T) {
+	el, err := ParseElement(strings.NewReader("{htmlElement: {typ: a}, settings: {attribute: href}, index: last}"))
+	require.NoError(t, err)
+	assert.Equal(t, Element{HtmlElement: HtmlElement{Typ: "a"}, Settings: Settings{Attribute: "href"}, Index: Last}, el)
+
+	_, err = ParseElement(strings.NewReader("{htmlElement: {typ: a, selektor: p}}"))
+	require.Error(t, err)
+	assert.Equal(t, ErrInvalidConfig, int(err.(Error).ErrType))
+	assert.Equal(t, `input:1:24: unknown field "selektor" of HtmlElement`, err.Error())
+}
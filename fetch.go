@@ -0,0 +1,173 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// Response defines the data structure for the response of a Fetcher
+type Response struct {
+	// URL is the final URL of the response, after following all redirects
+	URL        string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Fetcher defines the interface used by the scraper for fetching the HTML data of a URL,
+// implementations should abort the fetch once ctx is done
+type Fetcher interface {
+	Fetch(ctx context.Context, URL string) (*Response, error)
+}
+
+// FetcherFunc is an adapter to allow the use of ordinary functions as a Fetcher
+type FetcherFunc func(ctx context.Context, URL string) (*Response, error)
+
+// Fetch calls f(ctx, URL)
+func (f FetcherFunc) Fetch(ctx context.Context, URL string) (*Response, error) {
+	return f(ctx, URL)
+}
+
+// HTTPFetcher is the default Fetcher, fetching URLs using an http.Client
+type HTTPFetcher struct {
+	// Client is the http.Client used for the requests, http.DefaultClient will be used if Client is nil
+	Client *http.Client
+}
+
+// Fetch fetches URL using the http.Client of f
+func (f *HTTPFetcher) Fetch(ctx context.Context, URL string) (*Response, error) {
+	return f.FetchRequest(ctx, URL, nil)
+}
+
+// FetchRequest fetches URL using the method, header fields and body of r with the http.Client of f
+func (f *HTTPFetcher) FetchRequest(ctx context.Context, URL string, r *Request) (*Response, error) {
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var reqBody io.Reader
+	if r.body() != "" {
+		reqBody = strings.NewReader(r.body())
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method(), URL, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	if r != nil {
+		for key, value := range r.Header {
+			req.Header.Set(key, value)
+		}
+		// the Host header is not sent from req.Header
+		if host := req.Header.Get("Host"); host != "" {
+			req.Host = host
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		URL:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	}, nil
+}
+
+// FileFetcher is a Fetcher reading file:// URLs like file:///var/archive/page.html from the local file system,
+// responding with the status code 200 OK for every existing file, it has to be set as the Fetcher of a website
+// explicitly, as no other Fetcher reads local files
+type FileFetcher struct{}
+
+// Fetch reads the file of URL
+func (FileFetcher) Fetch(ctx context.Context, URL string) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(URL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "file" || (u.Host != "" && u.Host != "localhost") {
+		return nil, errors.New("unsupported URL " + URL + ", expected file:///path")
+	}
+	path := u.Path
+	if len(path) > 2 && path[0] == '/' && path[2] == ':' { // file:///C:/path on windows
+		path = path[1:]
+	}
+	path = filepath.FromSlash(path)
+
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	// the charset of the file is unknown, so only the media type is set
+	if typ, _, err := mime.ParseMediaType(mime.TypeByExtension(filepath.Ext(path))); err == nil {
+		header.Set("Content-Type", typ)
+	}
+	return &Response{URL: URL, StatusCode: http.StatusOK, Header: header, Body: body}, nil
+}
+
+// DefaultFetcher is the Fetcher used by GetHTML and by every Website without its own Fetcher
+var DefaultFetcher Fetcher = &HTTPFetcher{}
+
+// fetch fetches URL using req (see fetchRequest) with f, returning an error of type ErrHTTPStatus if the status code
+// of the response is not one of accepted, every 2xx status code is accepted if accepted is empty
+func fetch(ctx context.Context, f Fetcher, URL string, req *Request, accepted []int) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	resp, err := fetchRequest(ctx, f, URL, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !statusAccepted(resp.StatusCode, accepted) {
+		return nil, newStatusErr(resp)
+	}
+	return resp, nil
+}
+
+// statusAccepted returns whether code is one of accepted, or 2xx if accepted is empty
+func statusAccepted(code int, accepted []int) bool {
+	if len(accepted) == 0 {
+		return code >= 200 && code < 300
+	}
+	for _, c := range accepted {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// fetcherOf returns the Fetcher of w, falling back to inherited and then to DefaultFetcher
+func (w *Website) fetcherOf(inherited Fetcher) Fetcher {
+	if w.Fetcher != nil {
+		return w.Fetcher
+	}
+	if inherited != nil {
+		return inherited
+	}
+	return DefaultFetcher
+}
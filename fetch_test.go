@@ -0,0 +1,268 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapFetcher is a Fetcher serving the HTML data of its map, keyed by URL
+type mapFetcher map[string]string
+
+func (m mapFetcher) Fetch(ctx context.Context, URL string) (*Response, error) {
+	data, ok := m[URL]
+	if !ok {
+		return nil, errors.New("no page for " + URL)
+	}
+	return &Response{URL: URL, StatusCode: http.StatusOK, Header: http.Header{}, Body: []byte(data)}, nil
+}
+
+func TestHTTPFetcher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/page", http.StatusFound)
+			return
+		}
+		w.Header().Set("X-Test", "value")
+		w.Write([]byte("<p>page</p>"))
+	}))
+	defer server.Close()
+
+	resp, err := (&HTTPFetcher{Client: server.Client()}).Fetch(context.Background(), server.URL+"/redirect")
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/page", resp.URL)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "value", resp.Header.Get("X-Test"))
+	assert.Equal(t, "<p>page</p>", string(resp.Body))
+}
+
+func TestFetcher(t *testing.T) {
+	testMap := make(map[string]func(t *testing.T), 0)
+
+	testMap["websiteFetcher"] = func(t *testing.T) {
+		testWebsite := Website{
+			URL:     "https://example.com",
+			Fetcher: mapFetcher{"https://example.com": testHTML},
+			Elements: []Element{
+				{
+					HtmlElement: HtmlElement{
+						Typ: "p",
+						Tags: []Tag{
+							{
+								Typ:   "id",
+								Value: "singleElement_OneTag",
+							},
+						},
+					},
+				},
+			},
+		}
+
+		content, err := testWebsite.Scrape(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "This is the single element with one tag", content)
+	}
+	testMap["followedWebsiteInheritsFetcher"] = func(t *testing.T) {
+		testWebsite := Website{
+			URL: "https://example.com",
+			Fetcher: mapFetcher{
+				"https://example.com":                  testHTML,
+				"https://wikipedia.com/wiki/Wikipedia": `<h1 id="firstHeading">Wikipedia</h1>`,
+			},
+			Elements: []Element{
+				{
+					HtmlElement: HtmlElement{
+						Typ: "a",
+						Tags: []Tag{
+							{
+								Typ:   "id",
+								Value: "websiteLink",
+							},
+						},
+					},
+					ContentIsFollowURL: &Website{
+						Elements: []Element{
+							{
+								HtmlElement: HtmlElement{
+									Typ: "h1",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		content, err := testWebsite.Scrape(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Wikipedia", content)
+	}
+	testMap["defaultFetcher"] = func(t *testing.T) {
+		defaultFetcher := DefaultFetcher
+		defer func() { DefaultFetcher = defaultFetcher }()
+		DefaultFetcher = FetcherFunc(func(ctx context.Context, URL string) (*Response, error) {
+			return &Response{URL: URL, StatusCode: http.StatusOK, Body: []byte("<title>" + URL + "</title>")}, nil
+		})
+
+		data, err := GetHTML("https://example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "<title>https://example.com</title>", data)
+	}
+	testMap["fetcherError"] = func(t *testing.T) {
+		testWebsite := Website{
+			URL:     "https://example.com/missing",
+			Fetcher: mapFetcher{},
+		}
+
+		_, err := testWebsite.Scrape(nil)
+		require.Error(t, err)
+		assert.Equal(t, "no page for https://example.com/missing", err.Error())
+	}
+
+	for testName, testFunc := range testMap {
+		t.Run(testName, testFunc)
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	testMap := make(map[string]func(t *testing.T), 0)
+
+	statusFetcher := func(code int) Fetcher {
+		return FetcherFunc(func(ctx context.Context, URL string) (*Response, error) {
+			return &Response{URL: URL, StatusCode: code, Body: []byte(testHTML)}, nil
+		})
+	}
+	testElement := Element{
+		HtmlElement: HtmlElement{
+			Typ: "title",
+		},
+	}
+
+	testMap["notFound"] = func(t *testing.T) {
+		testWebsite := Website{
+			URL:      "https://example.com",
+			Fetcher:  statusFetcher(http.StatusNotFound),
+			Elements: []Element{testElement},
+		}
+
+		_, err := testWebsite.Scrape(nil)
+		require.Error(t, err)
+		var statusErr StatusError
+		require.True(t, errors.As(err, &statusErr))
+		assert.Equal(t, ErrHTTPStatus, int(statusErr.ErrType))
+		assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+		assert.Equal(t, "https://example.com", statusErr.URL)
+		assert.Equal(t, testHTML[:maxSnippetLen], statusErr.Snippet)
+		assert.Equal(t, "unexpected status code 404 for https://example.com", err.Error())
+	}
+	testMap["acceptedStatusCodes"] = func(t *testing.T) {
+		testWebsite := Website{
+			URL:                 "https://example.com",
+			Fetcher:             statusFetcher(http.StatusNotFound),
+			Elements:            []Element{testElement},
+			AcceptedStatusCodes: []int{http.StatusOK, http.StatusNotFound},
+		}
+
+		content, err := testWebsite.Scrape(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "TestHTML", content)
+	}
+	testMap["statusNotAccepted"] = func(t *testing.T) {
+		testWebsite := Website{
+			URL:                 "https://example.com",
+			Fetcher:             statusFetcher(http.StatusNoContent),
+			Elements:            []Element{testElement},
+			AcceptedStatusCodes: []int{http.StatusOK},
+		}
+
+		_, err := testWebsite.Scrape(nil)
+		var statusErr StatusError
+		require.True(t, errors.As(err, &statusErr))
+		assert.Equal(t, http.StatusNoContent, statusErr.StatusCode)
+	}
+	testMap["getHTML"] = func(t *testing.T) {
+		defaultFetcher := DefaultFetcher
+		defer func() { DefaultFetcher = defaultFetcher }()
+		DefaultFetcher = statusFetcher(http.StatusServiceUnavailable)
+
+		_, err := GetHTML("https://example.com")
+		var statusErr StatusError
+		require.True(t, errors.As(err, &statusErr))
+		assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+	}
+
+	for testName, testFunc := range testMap {
+		t.Run(testName, testFunc)
+	}
+}
+
+func TestFileFetcher(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "page.html")
+	require.NoError(t, os.WriteFile(path, []byte("<h1>Archived</h1>"), 0o644))
+	fileURL := (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+
+	resp, err := FileFetcher{}.Fetch(context.Background(), fileURL)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, fileURL, resp.URL)
+	assert.Equal(t, "<h1>Archived</h1>", string(resp.Body))
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+
+	// the DefaultFetcher does not read local files
+	_, err = GetHTML(fileURL)
+	assert.Error(t, err)
+
+	_, err = FileFetcher{}.Fetch(context.Background(), fileURL+".missing")
+	assert.Error(t, err)
+	_, err = FileFetcher{}.Fetch(context.Background(), "https://example.com")
+	assert.Error(t, err)
+}
+
+func TestFollowLocalFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secret.html")
+	require.NoError(t, os.WriteFile(path, []byte("<h1>Secret</h1>"), 0o644))
+	fileURL := (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<base href="file:///"><a href="%s">Secret</a>`, fileURL)
+	}))
+	defer server.Close()
+
+	// even a Fetcher reading local files must not follow them from a remote page
+	fetcher := FetcherFunc(func(ctx context.Context, URL string) (*Response, error) {
+		if strings.HasPrefix(URL, "file:") {
+			return FileFetcher{}.Fetch(ctx, URL)
+		}
+		return DefaultFetcher.Fetch(ctx, URL)
+	})
+	for _, f := range []Fetcher{nil, fetcher} {
+		testWebsite := Website{
+			URL:     server.URL,
+			Fetcher: f,
+			Elements: []Element{
+				{
+					HtmlElement:        HtmlElement{Typ: "a"},
+					Settings:           Settings{Attribute: "href"},
+					ContentIsFollowURL: &Website{Elements: []Element{{HtmlElement: HtmlElement{Typ: "h1"}}}},
+				},
+			},
+		}
+		content, err := testWebsite.Scrape(nil)
+		require.Error(t, err)
+		assert.NotContains(t, content, "Secret")
+		typ, _ := ErrTypeOf(err)
+		assert.Equal(t, ErrDisallowed, int(typ))
+	}
+}
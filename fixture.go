@@ -0,0 +1,157 @@
+package scraper
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// RecordEnv is the environment variable switching the FixtureFetcher of NewFixtureFetcher into ModeRecord,
+// e.g. GOSCRAPER_RECORD=1 go test ./...
+const RecordEnv = "GOSCRAPER_RECORD"
+
+// FixtureMode defines whether a FixtureFetcher records or replays responses
+type FixtureMode int
+
+const (
+	// ModeReplay serves the responses from the fixtures, failing for requests without a fixture
+	ModeReplay FixtureMode = iota
+	// ModeRecord fetches the responses and saves them as fixtures
+	ModeRecord
+)
+
+// FixtureFetcher is a Fetcher recording responses into a directory of fixtures and replaying them from there,
+// making scrapes deterministic and usable without network access, e.g. inside of tests
+type FixtureFetcher struct {
+	// Dir is the directory of the fixtures, one JSON file per request
+	Dir  string
+	Mode FixtureMode
+	// Fetcher fetches the responses to be recorded, DefaultFetcher will be used if Fetcher is nil
+	Fetcher Fetcher
+}
+
+// NewFixtureFetcher returns a FixtureFetcher for the fixtures inside of dir, recording the responses of fetcher
+// if the environment variable RecordEnv is set and replaying them otherwise
+func NewFixtureFetcher(dir string, fetcher Fetcher) *FixtureFetcher {
+	mode := ModeReplay
+	if os.Getenv(RecordEnv) != "" {
+		mode = ModeRecord
+	}
+	return &FixtureFetcher{Dir: dir, Mode: mode, Fetcher: fetcher}
+}
+
+// fixture defines the data structure of a recorded response
+type fixture struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// RequestBody is the body of the request, header fields of the request are not recorded
+	RequestBody string `json:"requestBody,omitempty"`
+	// FinalURL is the URL of the response, after following all redirects
+	FinalURL   string      `json:"finalURL"`
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body,omitempty"`
+	// BodyBase64 replaces Body if the body is not valid UTF-8
+	BodyBase64 string `json:"bodyBase64,omitempty"`
+}
+
+// Fetch replays the fixture of URL, or fetches and records it in ModeRecord
+func (f *FixtureFetcher) Fetch(ctx context.Context, URL string) (*Response, error) {
+	return f.FetchRequest(ctx, URL, nil)
+}
+
+// FetchRequest replays the fixture of the request of URL like Fetch, fixtures are told apart by the method,
+// URL and body of a request, but not by its header fields
+func (f *FixtureFetcher) FetchRequest(ctx context.Context, URL string, req *Request) (*Response, error) {
+	path := filepath.Join(f.Dir, fixtureName(req.method(), URL, req.body()))
+	if f.Mode == ModeRecord {
+		return f.record(ctx, path, URL, req)
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, newErr(ErrMissingFixture, "no fixture for "+req.method()+" "+URL+" inside of "+f.Dir+
+			", record it by setting "+RecordEnv)
+	} else if err != nil {
+		return nil, err
+	}
+
+	var fx fixture
+	if err := json.Unmarshal(data, &fx); err != nil {
+		return nil, err
+	}
+	body := []byte(fx.Body)
+	if fx.BodyBase64 != "" {
+		if body, err = base64.StdEncoding.DecodeString(fx.BodyBase64); err != nil {
+			return nil, err
+		}
+	}
+	return &Response{URL: fx.FinalURL, StatusCode: fx.StatusCode, Header: fx.Header, Body: body}, nil
+}
+
+// record fetches the request of URL and saves the response as the fixture at path
+func (f *FixtureFetcher) record(ctx context.Context, path, URL string, req *Request) (*Response, error) {
+	fetcher := f.Fetcher
+	if fetcher == nil {
+		fetcher = DefaultFetcher
+	}
+	resp, err := fetchRequest(ctx, fetcher, URL, req)
+	if err != nil {
+		return nil, err
+	}
+
+	fx := fixture{Method: req.method(), URL: URL, RequestBody: req.body(), FinalURL: resp.URL, StatusCode: resp.StatusCode, Header: resp.Header}
+	if utf8.Valid(resp.Body) {
+		fx.Body = string(resp.Body)
+	} else {
+		fx.BodyBase64 = base64.StdEncoding.EncodeToString(resp.Body)
+	}
+	data, err := json.MarshalIndent(fx, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// fixtureName returns the file name of the fixture of a request, being the readable host and path of URL
+// followed by a hash of the method, URL and body of the request
+func fixtureName(method, URL, body string) string {
+	readable := URL
+	if u, err := url.Parse(URL); err == nil {
+		readable = u.Host + u.Path
+	}
+	readable = strings.Trim(strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, readable), "_")
+	if len(readable) > 64 {
+		readable = readable[:64]
+	}
+
+	key := method + " " + URL
+	if body != "" {
+		// the body is only hashed if set, so requests without one keep their names
+		key += "\n" + body
+	}
+	sum := sha256.Sum256([]byte(key))
+	return readable + "-" + hex.EncodeToString(sum[:6]) + ".json"
+}
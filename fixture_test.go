@@ -0,0 +1,77 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixtureFetcher(t *testing.T) {
+	testMap := make(map[string]func(t *testing.T), 0)
+
+	testMap["recordAndReplay"] = func(t *testing.T) {
+		dir := t.TempDir()
+		upstream := FetcherFunc(func(ctx context.Context, URL string) (*Response, error) {
+			body := []byte("<h1>Hello</h1>")
+			if URL == "https://example.com/logo.png" {
+				body = []byte{0x89, 'P', 'N', 'G', 0xff}
+			}
+			return &Response{URL: URL + "?final", StatusCode: http.StatusOK, Header: http.Header{"X-Test": {"1"}}, Body: body}, nil
+		})
+
+		recorder := &FixtureFetcher{Dir: dir, Mode: ModeRecord, Fetcher: upstream}
+		replayer := &FixtureFetcher{Dir: dir}
+		for _, URL := range []string{"https://example.com/", "https://example.com/logo.png"} {
+			recorded, err := recorder.Fetch(context.Background(), URL)
+			require.NoError(t, err)
+			replayed, err := replayer.Fetch(context.Background(), URL)
+			require.NoError(t, err)
+			assert.Equal(t, recorded, replayed)
+		}
+	}
+	testMap["missingFixture"] = func(t *testing.T) {
+		testWebsite := Website{
+			URL:      "https://example.com/unknown",
+			Fetcher:  &FixtureFetcher{Dir: t.TempDir()},
+			Elements: []Element{{HtmlElement: HtmlElement{Typ: "h1"}}},
+		}
+		_, err := testWebsite.Scrape(nil)
+		require.Error(t, err)
+		assert.Equal(t, ErrMissingFixture, int(err.(Error).ErrType))
+		assert.Contains(t, err.Error(), "no fixture for GET https://example.com/unknown")
+	}
+	testMap["requestBodies"] = func(t *testing.T) {
+		dir := t.TempDir()
+		upstream := requestFetcherFunc(func(ctx context.Context, URL string, req *Request) (*Response, error) {
+			return &Response{URL: URL, StatusCode: http.StatusOK, Header: http.Header{}, Body: []byte(req.method() + " " + req.body())}, nil
+		})
+
+		recorder := &FixtureFetcher{Dir: dir, Mode: ModeRecord, Fetcher: upstream}
+		replayer := &FixtureFetcher{Dir: dir}
+		for _, body := range []string{"q=fruit", "q=vegetables"} {
+			req := &Request{Method: "post", Body: body}
+			_, err := recorder.FetchRequest(context.Background(), "https://example.com/search", req)
+			require.NoError(t, err)
+			replayed, err := replayer.FetchRequest(context.Background(), "https://example.com/search", req)
+			require.NoError(t, err)
+			assert.Equal(t, "POST "+body, string(replayed.Body))
+		}
+
+		_, err := replayer.Fetch(context.Background(), "https://example.com/search")
+		require.Error(t, err)
+		assert.Equal(t, ErrMissingFixture, int(err.(Error).ErrType))
+	}
+	testMap["modeFromEnv"] = func(t *testing.T) {
+		t.Setenv(RecordEnv, "")
+		assert.Equal(t, ModeReplay, NewFixtureFetcher("testdata", nil).Mode)
+		t.Setenv(RecordEnv, "1")
+		assert.Equal(t, ModeRecord, NewFixtureFetcher("testdata", nil).Mode)
+	}
+
+	for testName, testFunc := range testMap {
+		t.Run(testName, testFunc)
+	}
+}
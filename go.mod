@@ -5,10 +5,11 @@ go 1.18
 require (
 	github.com/stretchr/testify v1.7.1
 	golang.org/x/net v0.0.0-20220526153639-5463443f8c37
+	golang.org/x/text v0.13.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -1,7 +1,12 @@
 package scraper
 
 import (
+	"errors"
+	"net/http"
+	"strconv"
 	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 type ErrType int
@@ -13,8 +18,41 @@ const (
 	ErrNoNodeFound
 	// ErrIdxOutOfRange will be returned if the index of an array is out of range
 	ErrIdxOutOfRange
+	// ErrHTTPStatus will be returned if a website responds with a status code that is not accepted
+	ErrHTTPStatus
+	// ErrInvalidSelector will be returned if a selector or the match mode of a Tag is invalid
+	ErrInvalidSelector
+	// ErrInvalidXPath will be returned if an XPath expression could not be parsed
+	ErrInvalidXPath
+	// ErrMissingAttribute will be returned if an element does not have the attribute to be scraped
+	ErrMissingAttribute
+	// ErrInvalidIndex will be returned if an index or a slice could not be parsed
+	ErrInvalidIndex
+	// ErrUnmarshal will be returned if scraped data could not be stored inside of a Go value
+	ErrUnmarshal
+	// ErrInvalidConfig will be returned if a website definition could not be loaded or is invalid
+	ErrInvalidConfig
+	// ErrMissingFixture will be returned if a FixtureFetcher has no fixture for a request it has to replay
+	ErrMissingFixture
+	// ErrDisallowed will be returned if fetching a URL is not allowed, e.g. by the robots.txt of its host
+	// or for a file:// URL followed from a page which has not been read from a file itself
+	ErrDisallowed
 )
 
+// errType returns t, making the ErrType of every error embedding it available to ErrTypeOf
+func (t ErrType) errType() ErrType {
+	return t
+}
+
+// ErrTypeOf returns the ErrType of err (or of an error wrapped by it) and whether it has one
+func ErrTypeOf(err error) (ErrType, bool) {
+	var typed interface{ errType() ErrType }
+	if errors.As(err, &typed) {
+		return typed.errType(), true
+	}
+	return 0, false
+}
+
 // Error defines the data structure for a custom error
 type Error struct {
 	ErrType
@@ -31,6 +69,33 @@ func newErr(typ ErrType, msg string) Error {
 	return Error{ErrType: typ, msg: msg}
 }
 
+// StatusError defines the data structure for an error of type ErrHTTPStatus
+type StatusError struct {
+	ErrType
+	StatusCode int
+	URL        string
+	Header     http.Header
+	// Snippet contains the beginning of the response body
+	Snippet string
+}
+
+// Error returns the error msg of a StatusError
+func (e StatusError) Error() string {
+	return "unexpected status code " + strconv.Itoa(e.StatusCode) + " for " + e.URL
+}
+
+// maxSnippetLen is the maximum length of the body snippet of a StatusError
+const maxSnippetLen = 512
+
+// newStatusErr creates a new err of type ErrHTTPStatus for the response resp
+func newStatusErr(resp *Response) StatusError {
+	snippet := resp.Body
+	if len(snippet) > maxSnippetLen {
+		snippet = snippet[:maxSnippetLen]
+	}
+	return StatusError{ErrType: ErrHTTPStatus, StatusCode: resp.StatusCode, URL: resp.URL, Header: resp.Header, Snippet: string(snippet)}
+}
+
 // formatString replaces str with the return value of a func specified in funcs,
 // if str contains the map key of funcs one may use an array of variables
 // that can be passed into the function, as well, hence a function has to have
@@ -52,3 +117,104 @@ func formatString(str string, funcs map[string]interface{}, vars ...interface{})
 	}
 	return str
 }
+
+// Index defines the index of a node out of all nodes matching an element, negative indexes count
+// from the end, inside of JSON it may also be given as "first" or "last"
+type Index int
+
+const (
+	// First is the index of the first matching node
+	First Index = 0
+	// Last is the index of the last matching node
+	Last Index = -1
+)
+
+// UnmarshalJSON parses an Index from a JSON number, a numeric string or the named positions "first" and "last",
+// null leaves the Index unchanged
+func (i *Index) UnmarshalJSON(data []byte) (err error) {
+	if string(data) == "null" {
+		return nil
+	}
+	*i, err = parseIndex(strings.Trim(string(data), `"`))
+	return
+}
+
+// UnmarshalYAML parses an Index from a YAML scalar like UnmarshalJSON
+func (i *Index) UnmarshalYAML(value *yaml.Node) error {
+	if value.ShortTag() == "!!null" {
+		return nil
+	}
+	idx, err := parseIndex(value.Value)
+	if err != nil {
+		return &yaml.TypeError{Errors: []string{"line " + strconv.Itoa(value.Line) + ": " + err.Error()}}
+	}
+	*i = idx
+	return nil
+}
+
+// parseIndex parses an Index from a number or the named positions "first" and "last"
+func parseIndex(str string) (Index, error) {
+	switch str {
+	case "first":
+		return First, nil
+	case "last":
+		return Last, nil
+	}
+	idx, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, newErr(ErrInvalidIndex, "invalid index "+strconv.Quote(str))
+	}
+	return Index(idx), nil
+}
+
+// resolve returns the non-negative index of i inside of a sequence of length n and whether it is inside of the sequence
+func (i Index) resolve(n int) (int, bool) {
+	idx := int(i)
+	if idx < 0 {
+		idx += n
+	}
+	return idx, idx >= 0 && idx < n
+}
+
+// parseSlice returns the bounds of the python-style slice "start:end" inside of a sequence of length n,
+// negative bounds count from the end, an empty slice selects the whole sequence
+func parseSlice(slice string, n int) (start, end int, err error) {
+	if strings.TrimSpace(slice) == "" {
+		return 0, n, nil
+	}
+
+	parts := strings.Split(slice, ":")
+	if len(parts) != 2 {
+		return 0, 0, newErr(ErrInvalidIndex, "invalid slice "+strconv.Quote(slice))
+	}
+
+	bound := func(str string, def int) (int, error) {
+		if str = strings.TrimSpace(str); str == "" {
+			return def, nil
+		}
+		i, err := strconv.Atoi(str)
+		if err != nil {
+			return 0, newErr(ErrInvalidIndex, "invalid slice "+strconv.Quote(slice))
+		}
+		if i < 0 {
+			i += n
+		}
+		if i < 0 {
+			return 0, nil
+		} else if i > n {
+			return n, nil
+		}
+		return i, nil
+	}
+
+	if start, err = bound(parts[0], 0); err != nil {
+		return
+	}
+	if end, err = bound(parts[1], n); err != nil {
+		return
+	}
+	if end < start {
+		end = start
+	}
+	return
+}
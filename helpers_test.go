@@ -1,6 +1,9 @@
 package scraper
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"path"
 	"strconv"
 	"strings"
@@ -8,6 +11,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFormatString(t *testing.T) {
@@ -54,3 +58,74 @@ func TestFormatString(t *testing.T) {
 		assert.Equal(t, v, formatString(k, funcs, vars...))
 	}
 }
+
+func TestParseSlice(t *testing.T) {
+	testCases := map[string][2]int{
+		"":      {0, 5},
+		":":     {0, 5},
+		"1:":    {1, 5},
+		":3":    {0, 3},
+		"2:4":   {2, 4},
+		"-2:":   {3, 5},
+		":-1":   {0, 4},
+		"-10:2": {0, 2},
+		"3:100": {3, 5},
+		"4:2":   {4, 4},
+		" 1 : ": {1, 5},
+	}
+
+	for slice, expected := range testCases {
+		start, end, err := parseSlice(slice, 5)
+		require.NoError(t, err, slice)
+		assert.Equal(t, expected, [2]int{start, end}, slice)
+	}
+
+	for _, slice := range []string{"1", "a:", "1:2:3"} {
+		_, _, err := parseSlice(slice, 5)
+		require.Error(t, err, slice)
+		assert.Equal(t, ErrInvalidIndex, int(err.(Error).ErrType))
+		assert.Equal(t, "invalid slice "+strconv.Quote(slice), err.Error())
+	}
+}
+
+func TestIndexUnmarshalJSON(t *testing.T) {
+	testCases := map[string]Index{
+		`2`:       2,
+		`-1`:      -1,
+		`"-2"`:    -2,
+		`"first"`: First,
+		`"last"`:  Last,
+	}
+
+	for data, expected := range testCases {
+		var el Element
+		require.NoError(t, json.Unmarshal([]byte(`{"index": `+data+`}`), &el), data)
+		assert.Equal(t, expected, el.Index, data)
+	}
+
+	// null leaves the index unchanged
+	el := Element{Index: Last}
+	require.NoError(t, json.Unmarshal([]byte(`{"index": null}`), &el))
+	assert.Equal(t, Last, el.Index)
+	w, err := ParseWebsite(strings.NewReader("url: a\nelements:\n  - index: null\n  - index: ~\n"))
+	require.NoError(t, err)
+	assert.Equal(t, Index(0), w.Elements[0].Index)
+	assert.Equal(t, Index(0), w.Elements[1].Index)
+
+	err = json.Unmarshal([]byte(`{"index": "second"}`), &el)
+	require.Error(t, err)
+	assert.Equal(t, `invalid index "second"`, err.Error())
+}
+
+func TestErrTypeOf(t *testing.T) {
+	typ, ok := ErrTypeOf(fmt.Errorf("wrapped: %w", newErr(ErrInvalidXPath, "invalid")))
+	assert.True(t, ok)
+	assert.Equal(t, ErrInvalidXPath, int(typ))
+
+	typ, ok = ErrTypeOf(StatusError{ErrType: ErrHTTPStatus})
+	assert.True(t, ok)
+	assert.Equal(t, ErrHTTPStatus, int(typ))
+
+	_, ok = ErrTypeOf(errors.New("untyped"))
+	assert.False(t, ok)
+}
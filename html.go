@@ -2,48 +2,81 @@ package scraper
 
 import (
 	"bytes"
+	"context"
 	"io"
-	"io/ioutil"
-	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"golang.org/x/net/html"
 )
 
-// GetHTML returns the HTML data of URL
+// GetHTML returns the HTML data of URL, fetched using the DefaultFetcher and transcoded to UTF-8 (see DecodeHTML),
+// an error of type ErrHTTPStatus will be returned if the status code is not 2xx
 func GetHTML(URL string) (string, error) {
-	resp, err := http.Get(URL)
+	return GetHTMLContext(context.Background(), URL)
+}
+
+// GetHTMLContext returns the HTML data of URL like GetHTML, aborting once ctx is done
+func GetHTMLContext(ctx context.Context, URL string) (string, error) {
+	resp, err := fetch(ctx, DefaultFetcher, URL, nil, nil)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
-
-	html, err := ioutil.ReadAll(resp.Body)
+	body, err := DecodeHTML(resp.Body, resp.Header.Get("Content-Type"), "")
 	if err != nil {
 		return "", err
 	}
-
-	return string(html), nil
+	return string(body), nil
 }
 
-// GetHTMLNode returns the node tree of the html string data
+// GetHTMLNode returns the node tree of the html string data, which has to be UTF-8 (see DecodeHTML)
 func GetHTMLNode(data string) (*html.Node, error) {
 	return html.Parse(strings.NewReader(data))
 }
 
-// GetElementNodes returns an array of html.Node iniside of htmlNode having the same properties as element e
+// GetElementNodes returns an array of html.Node iniside of htmlNode (excluding htmlNode itself) having the same
+// properties as element e, or matching the Selector or the XPath (evaluated with htmlNode as its context node)
+// of e if one of them is set
 func (e *HtmlElement) GetElementNodes(htmlNode *html.Node) ([]*html.Node, error) {
+	if e.Selector != "" {
+		sel, err := CompileSelector(e.Selector)
+		if err != nil {
+			return nil, err
+		}
+		if el := sel.Select(htmlNode); len(el) > 0 {
+			return el, nil
+		}
+		return nil, newErr(ErrMissingElement, "missing "+e.Selector+" in the node tree")
+	}
+
+	if e.XPath != "" {
+		expr, err := CompileXPath(e.XPath)
+		if err != nil {
+			return nil, err
+		}
+		if el := expr.Select(htmlNode); len(el) > 0 {
+			return el, nil
+		}
+		return nil, newErr(ErrMissingElement, "missing "+e.XPath+" in the node tree")
+	}
+
+	matchers := make([]func(*html.Node) bool, len(e.Tags))
+	for k, tag := range e.Tags {
+		matcher, err := tag.matcher()
+		if err != nil {
+			return nil, err
+		}
+		matchers[k] = matcher
+	}
+
 	var crawler func(*html.Node) []*html.Node
 	crawler = func(node *html.Node) (elements []*html.Node) {
 		if node.Type == html.ElementNode && node.Data == e.Typ {
 			var foundTags int
-			for _, tag := range e.Tags {
-			C:
-				for _, attr := range node.Attr {
-					if attr.Key == tag.Typ && attr.Val == tag.Value {
-						foundTags += 1
-						break C
-					}
+			for _, matches := range matchers {
+				if matches(node) {
+					foundTags += 1
 				}
 			}
 			if len(e.Tags) == foundTags { // has found all tags of node
@@ -55,12 +88,52 @@ func (e *HtmlElement) GetElementNodes(htmlNode *html.Node) ([]*html.Node, error)
 		}
 		return elements
 	}
-	if el := crawler(htmlNode); len(el) > 0 {
-		return el, nil
+	var elements []*html.Node
+	for child := htmlNode.FirstChild; child != nil; child = child.NextSibling {
+		elements = append(elements, crawler(child)...)
+	}
+	if len(elements) > 0 {
+		return elements, nil
 	}
 	return nil, newErr(ErrMissingElement, "missing "+e.Typ+" in the node tree")
 }
 
+// matcher returns a func reporting whether a node has an attribute matching tag t
+func (t Tag) matcher() (func(*html.Node) bool, error) {
+	var compare func(val string) bool
+	switch t.Match {
+	case "", MatchEquals:
+		compare = func(val string) bool { return val == t.Value }
+	case MatchToken:
+		compare = func(val string) bool { return containsField(val, t.Value) }
+	case MatchPrefix:
+		compare = func(val string) bool { return strings.HasPrefix(val, t.Value) }
+	case MatchSuffix:
+		compare = func(val string) bool { return strings.HasSuffix(val, t.Value) }
+	case MatchSubstring:
+		compare = func(val string) bool { return strings.Contains(val, t.Value) }
+	case MatchRegex:
+		re, err := regexp.Compile(t.Value)
+		if err != nil {
+			return nil, newErr(ErrInvalidSelector, "invalid regex of tag "+t.Typ+": "+err.Error())
+		}
+		compare = re.MatchString
+	case MatchPresent:
+		compare = func(val string) bool { return true }
+	default:
+		return nil, newErr(ErrInvalidSelector, "invalid match mode "+string(t.Match)+" of tag "+t.Typ)
+	}
+
+	return func(node *html.Node) bool {
+		for _, attr := range node.Attr {
+			if attr.Key == t.Typ && compare(attr.Val) {
+				return !t.Not
+			}
+		}
+		return t.Not
+	}, nil
+}
+
 // RenderNode returns the string representation of an html.Node
 func RenderNode(node *html.Node) string {
 	var buf bytes.Buffer
@@ -83,3 +156,42 @@ func GetTextOfNode(node *html.Node, notRecursive bool) (text string) {
 	}
 	return
 }
+
+// GetAttributeOfNode returns the value of the attribute key of an html element and whether the element has the attribute
+func GetAttributeOfNode(node *html.Node, key string) (string, bool) {
+	for _, attr := range node.Attr {
+		if attr.Namespace == "" && attr.Key == key {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+// NodePath returns a CSS selector like "html > body > ul > li:nth-of-type(2)" leading to node,
+// nodes other than elements are described by the path of their parent element
+func NodePath(node *html.Node) string {
+	var parts []string
+	for n := node; n != nil; n = n.Parent {
+		if n.Type != html.ElementNode {
+			continue
+		}
+
+		part := n.Data
+		var pos, count int
+		if n.Parent != nil {
+			for sibling := n.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+				if sibling.Type == html.ElementNode && sibling.Data == n.Data {
+					count++
+					if sibling == n {
+						pos = count
+					}
+				}
+			}
+		}
+		if count > 1 {
+			part += ":nth-of-type(" + strconv.Itoa(pos) + ")"
+		}
+		parts = append([]string{part}, parts...)
+	}
+	return strings.Join(parts, " > ")
+}
@@ -230,3 +230,121 @@ func TestGetTextOfNode(t *testing.T) {
 		t.Run(testName, testFunc)
 	}
 }
+
+func TestGetAttributeOfNode(t *testing.T) {
+	documentNode, err := GetHTMLNode(testHTML)
+	require.NoError(t, err)
+
+	testElement := HtmlElement{
+		Typ: "p",
+		Tags: []Tag{
+			{
+				Typ:   "id",
+				Value: "singleElement_MultipleTags",
+			},
+		},
+	}
+	nodes, err := testElement.GetElementNodes(documentNode)
+	require.NoError(t, err)
+	node := nodes[0]
+
+	val, ok := GetAttributeOfNode(node, "class")
+	assert.True(t, ok)
+	assert.Equal(t, "multipleTags", val)
+
+	val, ok = GetAttributeOfNode(node, "href")
+	assert.False(t, ok)
+	assert.Equal(t, "", val)
+}
+
+func TestGetElementNodesMatchModes(t *testing.T) {
+	documentNode, err := GetHTMLNode(`
+	<div>
+		<button class="btn primary" id="save-button">Save</button>
+		<button class="btn" id="cancel-button" disabled>Cancel</button>
+		<button class="button-link" id="help">Help</button>
+	</div>`)
+	require.NoError(t, err)
+
+	textsOf := func(t *testing.T, tags ...Tag) []string {
+		testElement := HtmlElement{
+			Typ:  "button",
+			Tags: tags,
+		}
+		nodes, err := testElement.GetElementNodes(documentNode)
+		if err != nil {
+			return nil
+		}
+		var texts []string
+		for _, node := range nodes {
+			texts = append(texts, GetTextOfNode(node, false))
+		}
+		return texts
+	}
+
+	testCases := map[string]struct {
+		tags     []Tag
+		expected []string
+	}{
+		"equals":          {[]Tag{{Typ: "class", Value: "btn"}}, []string{"Cancel"}},
+		"explicitEquals":  {[]Tag{{Typ: "class", Value: "btn", Match: MatchEquals}}, []string{"Cancel"}},
+		"token":           {[]Tag{{Typ: "class", Value: "btn", Match: MatchToken}}, []string{"Save", "Cancel"}},
+		"prefix":          {[]Tag{{Typ: "class", Value: "btn", Match: MatchPrefix}}, []string{"Save", "Cancel"}},
+		"suffix":          {[]Tag{{Typ: "id", Value: "-button", Match: MatchSuffix}}, []string{"Save", "Cancel"}},
+		"substring":       {[]Tag{{Typ: "class", Value: "button", Match: MatchSubstring}}, []string{"Help"}},
+		"regex":           {[]Tag{{Typ: "id", Value: "^(save|help)", Match: MatchRegex}}, []string{"Save", "Help"}},
+		"present":         {[]Tag{{Typ: "disabled", Match: MatchPresent}}, []string{"Cancel"}},
+		"notPresent":      {[]Tag{{Typ: "disabled", Match: MatchPresent, Not: true}}, []string{"Save", "Help"}},
+		"notToken":        {[]Tag{{Typ: "class", Value: "primary", Match: MatchToken, Not: true}}, []string{"Cancel", "Help"}},
+		"multipleMatches": {[]Tag{{Typ: "class", Value: "btn", Match: MatchToken}, {Typ: "id", Value: "cancel", Match: MatchPrefix, Not: true}}, []string{"Save"}},
+	}
+
+	for testName, tc := range testCases {
+		tc := tc
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, tc.expected, textsOf(t, tc.tags...))
+		})
+	}
+
+	testMap := make(map[string]func(t *testing.T), 0)
+
+	testMap["invalidRegex"] = func(t *testing.T) {
+		testElement := HtmlElement{
+			Typ:  "button",
+			Tags: []Tag{{Typ: "id", Value: "(", Match: MatchRegex}},
+		}
+		_, err := testElement.GetElementNodes(documentNode)
+		require.Error(t, err)
+		assert.Equal(t, ErrInvalidSelector, int(err.(Error).ErrType))
+	}
+	testMap["invalidMatchMode"] = func(t *testing.T) {
+		testElement := HtmlElement{
+			Typ:  "button",
+			Tags: []Tag{{Typ: "id", Value: "help", Match: "fuzzy"}},
+		}
+		_, err := testElement.GetElementNodes(documentNode)
+		require.Error(t, err)
+		assert.Equal(t, "invalid match mode fuzzy of tag id", err.Error())
+	}
+
+	for testName, testFunc := range testMap {
+		t.Run(testName, testFunc)
+	}
+}
+
+func TestNodePath(t *testing.T) {
+	documentNode, err := GetHTMLNode(`<ul><li>Apple</li><li>Pear <b>ripe</b></li></ul>`)
+	require.NoError(t, err)
+
+	testElement := HtmlElement{Selector: "b"}
+	nodes, err := testElement.GetElementNodes(documentNode)
+	require.NoError(t, err)
+
+	path := NodePath(nodes[0])
+	assert.Equal(t, "html > body > ul > li:nth-of-type(2) > b", path)
+	assert.Equal(t, path, NodePath(nodes[0].FirstChild))
+
+	sel, err := CompileSelector(path)
+	require.NoError(t, err)
+	assert.Equal(t, nodes, sel.Select(documentNode))
+}
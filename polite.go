@@ -0,0 +1,220 @@
+package scraper
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Politeness defines the data structure for the limits of a PoliteFetcher, which apply to every host on its own
+type Politeness struct {
+	// RequestsPerSecond limits the rate of requests, 0 disables the limit
+	RequestsPerSecond float64
+	// Burst is the number of requests which may be sent at once before RequestsPerSecond applies, defaults to 1
+	Burst int
+	// MinDelay is the minimum delay between the starts of two requests
+	MinDelay time.Duration
+	// MaxConcurrent limits the number of requests being fetched at the same time, 0 disables the limit
+	MaxConcurrent int
+	// Robots enables honoring the robots.txt of every host, refusing disallowed URLs with an error of type
+	// ErrDisallowed and raising MinDelay to the Crawl-delay of the host
+	Robots bool
+	// UserAgent is sent as the User-Agent header of every request not setting one itself, defaults to DefaultUserAgent,
+	// the robots.txt files are matched against the user agent actually sent
+	UserAgent string
+}
+
+// PoliteFetcher is a Fetcher passing requests on to Fetcher within the limits of its Politeness for every host,
+// it may be shared by multiple websites (and goroutines), limiting all of their requests together
+type PoliteFetcher struct {
+	// Fetcher fetches the requests, DefaultFetcher will be used if Fetcher is nil
+	Fetcher Fetcher
+	Politeness
+
+	mu    sync.Mutex
+	hosts map[string]*hostLimiter
+}
+
+// NewPoliteFetcher returns a PoliteFetcher fetching the requests using f within the limits of politeness
+func NewPoliteFetcher(f Fetcher, politeness Politeness) *PoliteFetcher {
+	return &PoliteFetcher{Fetcher: f, Politeness: politeness}
+}
+
+// hostLimiter defines the state of the requests to a single host
+type hostLimiter struct {
+	// tokens is the number of requests which may be sent right away, negative if requests are waiting
+	tokens float64
+	// updated is the time tokens has been updated at
+	updated time.Time
+	// lastStart is the time the latest request has been scheduled to start at
+	lastStart time.Time
+	// slots limits the number of concurrent requests, nil if unlimited
+	slots chan struct{}
+	// robots is the robots.txt of the host, nil until it has been fetched
+	robots   *RobotsTxt
+	robotsMu sync.Mutex
+}
+
+// Fetch fetches URL using the Fetcher of f, waiting until the limits of its host allow the request
+func (f *PoliteFetcher) Fetch(ctx context.Context, URL string) (*Response, error) {
+	return f.FetchRequest(ctx, URL, nil)
+}
+
+// FetchRequest fetches URL using req like Fetch, the Fetcher of f has to be a RequestFetcher unless req is plain
+func (f *PoliteFetcher) FetchRequest(ctx context.Context, URL string, req *Request) (*Response, error) {
+	fetcher := f.Fetcher
+	if fetcher == nil {
+		fetcher = DefaultFetcher
+	}
+
+	h, u := f.limiter(URL)
+	if h == nil {
+		return fetchRequest(ctx, fetcher, URL, req)
+	}
+
+	if h.slots != nil {
+		select {
+		case h.slots <- struct{}{}:
+			defer func() { <-h.slots }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	agent, req := f.identify(fetcher, req)
+	minDelay := f.MinDelay
+	if f.Robots {
+		robots, err := f.robotsTxt(ctx, fetcher, h, u, agent)
+		if err != nil {
+			return nil, err
+		}
+		if !robots.Allowed(agent, URL) {
+			return nil, newErr(ErrDisallowed, URL+" is disallowed by the robots.txt of "+u.Host)
+		}
+		if delay := robots.CrawlDelay(agent); delay > minDelay {
+			minDelay = delay
+		}
+	}
+
+	if err := f.wait(ctx, h, minDelay); err != nil {
+		return nil, err
+	}
+	return fetchRequest(ctx, fetcher, URL, req)
+}
+
+// wait waits until the limits of h allow the next request, keeping minDelay between the requests
+func (f *PoliteFetcher) wait(ctx context.Context, h *hostLimiter, minDelay time.Duration) error {
+	delay := f.reserve(h, minDelay)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// limiter returns the hostLimiter of the host of URL together with the parsed URL, or nil if URL has no host
+func (f *PoliteFetcher) limiter(URL string) (*hostLimiter, *url.URL) {
+	u, err := url.Parse(URL)
+	if err != nil || u.Host == "" {
+		return nil, nil
+	}
+	host := strings.ToLower(u.Host)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.hosts == nil {
+		f.hosts = make(map[string]*hostLimiter)
+	}
+	h, ok := f.hosts[host]
+	if !ok {
+		h = &hostLimiter{tokens: float64(f.burst()), updated: time.Now()}
+		if f.MaxConcurrent > 0 {
+			h.slots = make(chan struct{}, f.MaxConcurrent)
+		}
+		f.hosts[host] = h
+	}
+	return h, u
+}
+
+// reserve schedules the start of a request to h at least minDelay after the previous one,
+// returning the delay until the request may start
+func (f *PoliteFetcher) reserve(h *hostLimiter, minDelay time.Duration) time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	start := now
+	if rps := f.RequestsPerSecond; rps > 0 {
+		h.tokens += now.Sub(h.updated).Seconds() * rps
+		if burst := float64(f.burst()); h.tokens > burst {
+			h.tokens = burst
+		}
+		h.updated = now
+
+		h.tokens--
+		if h.tokens < 0 {
+			start = now.Add(time.Duration(-h.tokens / rps * float64(time.Second)))
+		}
+	}
+	if earliest := h.lastStart.Add(minDelay); minDelay > 0 && start.Before(earliest) {
+		start = earliest
+	}
+	h.lastStart = start
+	return start.Sub(now)
+}
+
+// identify returns the user agent req is sent with together with req, which gets the User-Agent header of f
+// if it does not set one itself and fetcher is a RequestFetcher able to send it
+func (f *PoliteFetcher) identify(fetcher Fetcher, req *Request) (string, *Request) {
+	if req != nil {
+		for key, value := range req.Header {
+			if strings.EqualFold(key, "User-Agent") {
+				return value, req
+			}
+		}
+	}
+	if _, ok := fetcher.(RequestFetcher); !ok {
+		return f.userAgent(), req
+	}
+	return f.userAgent(), req.withUserAgent(f.userAgent())
+}
+
+// withUserAgent returns a copy of r having the User-Agent header agent
+func (r *Request) withUserAgent(agent string) *Request {
+	req := &Request{}
+	if r != nil {
+		*req = *r
+	}
+	req.Header = make(map[string]string, len(req.Header)+1)
+	if r != nil {
+		for key, value := range r.Header {
+			req.Header[key] = value
+		}
+	}
+	req.Header["User-Agent"] = agent
+	return req
+}
+
+// userAgent returns the UserAgent of f, or DefaultUserAgent if it is not set
+func (f *PoliteFetcher) userAgent() string {
+	if f.UserAgent != "" {
+		return f.UserAgent
+	}
+	return DefaultUserAgent
+}
+
+// burst returns the Burst of f, or 1 if it is not set
+func (f *PoliteFetcher) burst() int {
+	if f.Burst > 0 {
+		return f.Burst
+	}
+	return 1
+}
@@ -0,0 +1,129 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingFetcher is a Fetcher recording the start of every request and the maximum number of concurrent requests
+type recordingFetcher struct {
+	mu       sync.Mutex
+	starts   map[string][]time.Time
+	inFlight int32
+	max      int32
+	duration time.Duration
+}
+
+func (f *recordingFetcher) Fetch(ctx context.Context, URL string) (*Response, error) {
+	f.mu.Lock()
+	if f.starts == nil {
+		f.starts = make(map[string][]time.Time)
+	}
+	f.starts[URL] = append(f.starts[URL], time.Now())
+	f.mu.Unlock()
+
+	n := atomic.AddInt32(&f.inFlight, 1)
+	defer atomic.AddInt32(&f.inFlight, -1)
+	for {
+		max := atomic.LoadInt32(&f.max)
+		if n <= max || atomic.CompareAndSwapInt32(&f.max, max, n) {
+			break
+		}
+	}
+	time.Sleep(f.duration)
+	return &Response{URL: URL, StatusCode: http.StatusOK, Header: http.Header{}, Body: []byte("<h1>Page</h1>")}, nil
+}
+
+// fetchAll fetches every URL of URLs n times, all at the same time
+func fetchAll(t *testing.T, f Fetcher, n int, URLs ...string) {
+	var wg sync.WaitGroup
+	for _, URL := range URLs {
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(URL string) {
+				defer wg.Done()
+				_, err := f.Fetch(context.Background(), URL)
+				assert.NoError(t, err)
+			}(URL)
+		}
+	}
+	wg.Wait()
+}
+
+func TestPoliteFetcher(t *testing.T) {
+	testMap := make(map[string]func(t *testing.T), 0)
+
+	testMap["requestsPerSecond"] = func(t *testing.T) {
+		recorder := &recordingFetcher{}
+		start := time.Now()
+		fetchAll(t, NewPoliteFetcher(recorder, Politeness{RequestsPerSecond: 50, Burst: 2}), 4, "https://a.example.com/")
+		// two requests of the burst are sent right away, the other two are spaced by 20ms
+		assert.GreaterOrEqual(t, time.Since(start), 35*time.Millisecond)
+		assert.Len(t, recorder.starts["https://a.example.com/"], 4)
+	}
+	testMap["minDelayPerHost"] = func(t *testing.T) {
+		recorder := &recordingFetcher{}
+		start := time.Now()
+		fetchAll(t, NewPoliteFetcher(recorder, Politeness{MinDelay: 20 * time.Millisecond}), 3, "https://a.example.com/", "https://b.example.com/")
+		elapsed := time.Since(start)
+		// both hosts are limited on their own, so three requests take two delays
+		assert.GreaterOrEqual(t, elapsed, 40*time.Millisecond)
+		assert.Less(t, elapsed, 200*time.Millisecond)
+
+		for _, starts := range recorder.starts {
+			require.Len(t, starts, 3)
+		}
+	}
+	testMap["maxConcurrent"] = func(t *testing.T) {
+		recorder := &recordingFetcher{duration: 5 * time.Millisecond}
+		fetchAll(t, NewPoliteFetcher(recorder, Politeness{MaxConcurrent: 2}), 6, "https://a.example.com/")
+		assert.Equal(t, int32(2), recorder.max)
+	}
+	testMap["canceled"] = func(t *testing.T) {
+		f := NewPoliteFetcher(&recordingFetcher{}, Politeness{MinDelay: time.Hour})
+		_, err := f.Fetch(context.Background(), "https://a.example.com/")
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = f.Fetch(ctx, "https://a.example.com/")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+	testMap["followedWebsites"] = func(t *testing.T) {
+		f := NewPoliteFetcher(mapFetcher{
+			"https://example.com":   `<a href="/a">A</a><a href="/b">B</a>`,
+			"https://example.com/a": `<h1>A</h1>`,
+			"https://example.com/b": `<h1>B</h1>`,
+		}, Politeness{MinDelay: 15 * time.Millisecond})
+		testWebsite := Website{
+			URL:       "https://example.com",
+			Separator: ", ",
+			Fetcher:   f,
+			Elements: []Element{
+				{
+					HtmlElement:        HtmlElement{Typ: "a"},
+					Settings:           Settings{Attribute: "href"},
+					All:                true,
+					ContentIsFollowURL: &Website{Elements: []Element{{HtmlElement: HtmlElement{Typ: "h1"}}}},
+				},
+			},
+		}
+
+		start := time.Now()
+		content, err := testWebsite.Scrape(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "A, B", content)
+		assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+	}
+
+	for testName, testFunc := range testMap {
+		t.Run(testName, testFunc)
+	}
+}
@@ -0,0 +1,176 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Request defines the data structure for the HTTP request of a website
+type Request struct {
+	// Method is the HTTP method of the request, defaults to GET
+	Method string `json:"method" yaml:"method"`
+	// Header contains the header fields of the request, e.g. User-Agent, Accept-Language or Cookie,
+	// a followed website inherits the Header of its parent, with its own fields taking precedence,
+	// except for credentials like Authorization or Cookie and Host if it is on another host
+	Header map[string]string `json:"header" yaml:"header"`
+	// Query contains the query parameters added to the URL of the website
+	Query map[string]string `json:"query" yaml:"query"`
+	// Body is the body of the request, e.g. url-encoded form data for POST requests together with a
+	// Content-Type header of application/x-www-form-urlencoded
+	Body string `json:"body" yaml:"body"`
+}
+
+// RequestFetcher is implemented by every Fetcher supporting the Request of a website, other Fetchers are only
+// able to fetch GET requests without header fields or a body
+type RequestFetcher interface {
+	Fetcher
+	// FetchRequest fetches URL using the method, header fields and body of req, which may be nil for a GET request,
+	// the Query of req has already been added to URL
+	FetchRequest(ctx context.Context, URL string, req *Request) (*Response, error)
+}
+
+// method returns the Method of r in upper case, or GET if r is nil or the Method is not set
+func (r *Request) method() string {
+	if r == nil || r.Method == "" {
+		return http.MethodGet
+	}
+	return strings.ToUpper(r.Method)
+}
+
+// body returns the Body of r, or an empty string if r is nil
+func (r *Request) body() string {
+	if r == nil {
+		return ""
+	}
+	return r.Body
+}
+
+// idempotent reports whether the method of r is idempotent, so sending r multiple times has the same effect as once
+func (r *Request) idempotent() bool {
+	switch r.method() {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// plain reports whether r is a GET request without header fields or a body, which every Fetcher is able to fetch
+func (r *Request) plain() bool {
+	return r == nil || (r.method() == http.MethodGet && len(r.Header) == 0 && r.Body == "")
+}
+
+// format returns a copy of r with every string formatted using funcs (see formatString)
+func (r *Request) format(funcs map[string]interface{}, vars ...interface{}) *Request {
+	if r == nil {
+		return nil
+	}
+	formatMap := func(m map[string]string) map[string]string {
+		if m == nil {
+			return nil
+		}
+		formatted := make(map[string]string, len(m))
+		for key, value := range m {
+			formatted[key] = formatString(value, funcs, vars...)
+		}
+		return formatted
+	}
+	return &Request{
+		Method: formatString(r.Method, funcs, vars...),
+		Header: formatMap(r.Header),
+		Query:  formatMap(r.Query),
+		Body:   formatString(r.Body, funcs, vars...),
+	}
+}
+
+// credentialHeaders lists the header fields not inherited by a followed website on another host, like the ones
+// net/http drops when following a redirect to another domain
+var credentialHeaders = map[string]bool{
+	"Authorization":       true,
+	"Cookie":              true,
+	"Cookie2":             true,
+	"Host":                true,
+	"Proxy-Authorization": true,
+	"Www-Authenticate":    true,
+}
+
+// inherit returns a copy of r having the Header fields of parent, which r does not set itself, the credentials and
+// Host of parent are only inherited if URL has the same scheme and host as parentURL, the URL of the parent page
+func (r *Request) inherit(parent *Request, parentURL, URL string) *Request {
+	if parent == nil || len(parent.Header) == 0 {
+		return r
+	}
+	req := &Request{}
+	if r != nil {
+		*req = *r
+	}
+	same := sameHost(parentURL, URL)
+	req.Header = make(map[string]string, len(parent.Header))
+	for key, value := range parent.Header {
+		key = http.CanonicalHeaderKey(key)
+		if !same && credentialHeaders[key] {
+			continue
+		}
+		req.Header[key] = value
+	}
+	if r != nil {
+		for key, value := range r.Header {
+			req.Header[http.CanonicalHeaderKey(key)] = value
+		}
+	}
+	return req
+}
+
+// sameHost reports whether the URLs a and b have the same scheme and host, including the port
+func sameHost(a, b string) bool {
+	u, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	v, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Scheme, v.Scheme) && strings.EqualFold(u.Host, v.Host)
+}
+
+// addQuery returns URL with the Query parameters of r added, sorted by their name
+func (r *Request) addQuery(URL string) (string, error) {
+	if r == nil || len(r.Query) == 0 {
+		return URL, nil
+	}
+	u, err := url.Parse(URL)
+	if err != nil {
+		return "", err
+	}
+
+	names := make([]string, 0, len(r.Query))
+	for name := range r.Query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	query := u.RawQuery
+	for _, name := range names {
+		if query != "" {
+			query += "&"
+		}
+		query += url.QueryEscape(name) + "=" + url.QueryEscape(r.Query[name])
+	}
+	u.RawQuery = query
+	return u.String(), nil
+}
+
+// fetchRequest fetches URL using req with f, which has to be a RequestFetcher unless req is plain
+func fetchRequest(ctx context.Context, f Fetcher, URL string, req *Request) (*Response, error) {
+	if rf, ok := f.(RequestFetcher); ok {
+		return rf.FetchRequest(ctx, URL, req)
+	}
+	if !req.plain() {
+		return nil, newErr(ErrInvalidConfig, "the Fetcher does not support the "+req.method()+" request of "+URL+
+			", it has to implement RequestFetcher")
+	}
+	return f.Fetch(ctx, URL)
+}
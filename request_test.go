@@ -0,0 +1,138 @@
+package scraper
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requestFetcherFunc is a RequestFetcher calling the func itself for every request
+type requestFetcherFunc func(ctx context.Context, URL string, req *Request) (*Response, error)
+
+func (f requestFetcherFunc) Fetch(ctx context.Context, URL string) (*Response, error) {
+	return f(ctx, URL, nil)
+}
+
+func (f requestFetcherFunc) FetchRequest(ctx context.Context, URL string, req *Request) (*Response, error) {
+	return f(ctx, URL, req)
+}
+
+func TestRequest(t *testing.T) {
+	testMap := make(map[string]func(t *testing.T), 0)
+
+	testMap["httpFetcher"] = func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/next" {
+				io.WriteString(w, "<h1>"+r.Method+" "+r.Header.Get("Accept-Language")+" "+r.Header.Get("Referer")+"</h1>")
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			io.WriteString(w, `<a href="/next">`+r.Method+" "+r.URL.RawQuery+" "+r.Header.Get("Accept-Language")+" "+string(body)+"</a>")
+		}))
+		defer server.Close()
+
+		testWebsite := Website{
+			URL:       server.URL + "/search?page=1",
+			Separator: " | ",
+			Request: &Request{
+				Method: "post",
+				Header: map[string]string{"accept-language": "{{lang}}"},
+				Query:  map[string]string{"q": "{{query}}", "a b": "c&d"},
+				Body:   "q={{query}}",
+			},
+			Elements: []Element{
+				{HtmlElement: HtmlElement{Typ: "a"}},
+				{
+					HtmlElement: HtmlElement{Typ: "a"},
+					Settings:    Settings{Attribute: "href"},
+					// the followed website inherits the header fields, but neither the method nor the body
+					ContentIsFollowURL: &Website{
+						Request:  &Request{Header: map[string]string{"Referer": "goScraper"}},
+						Elements: []Element{{HtmlElement: HtmlElement{Typ: "h1"}}},
+					},
+				},
+			},
+		}
+
+		funcs := map[string]interface{}{
+			"{{lang}}":  func(str string) string { return strings.ReplaceAll(str, "{{lang}}", "de-DE") },
+			"{{query}}": func(str string) string { return strings.ReplaceAll(str, "{{query}}", "fruit") },
+		}
+		content, err := testWebsite.Scrape(&funcs)
+		require.NoError(t, err)
+		assert.Equal(t, "POST page=1&a+b=c%26d&q=fruit de-DE q=fruit | GET de-DE goScraper", content)
+		// the Request of the website itself is not formatted
+		assert.Equal(t, "{{lang}}", testWebsite.Request.Header["accept-language"])
+	}
+	testMap["unsupportedFetcher"] = func(t *testing.T) {
+		testWebsite := Website{
+			URL:      "https://example.com",
+			Request:  &Request{Header: map[string]string{"User-Agent": "goScraper"}},
+			Fetcher:  mapFetcher{"https://example.com": "<h1>Page</h1>"},
+			Elements: []Element{{HtmlElement: HtmlElement{Typ: "h1"}}},
+		}
+		_, err := testWebsite.Scrape(nil)
+		require.Error(t, err)
+		assert.Equal(t, ErrInvalidConfig, int(err.(Error).ErrType))
+
+		// a plain GET request is supported by every Fetcher
+		testWebsite.Request = &Request{Method: "get", Query: map[string]string{"page": "2"}}
+		testWebsite.Fetcher = mapFetcher{"https://example.com?page=2": "<h1>Page 2</h1>"}
+		content, err := testWebsite.Scrape(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Page 2", content)
+	}
+	testMap["otherHost"] = func(t *testing.T) {
+		other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, "<h1>"+r.Host+" "+r.Header.Get("Authorization")+" "+r.Header.Get("Cookie")+" "+r.Header.Get("Accept-Language")+"</h1>")
+		}))
+		defer other.Close()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/next" {
+				io.WriteString(w, "<h1>"+r.Header.Get("Authorization")+" "+r.Header.Get("Cookie")+"</h1>")
+				return
+			}
+			io.WriteString(w, `<a href="/next">Next</a><a href="`+other.URL+`/">Other</a>`)
+		}))
+		defer server.Close()
+
+		follow := func(index Index) Element {
+			return Element{
+				HtmlElement:        HtmlElement{Typ: "a"},
+				Index:              index,
+				Settings:           Settings{Attribute: "href"},
+				ContentIsFollowURL: &Website{Elements: []Element{{HtmlElement: HtmlElement{Typ: "h1"}}}},
+			}
+		}
+		testWebsite := Website{
+			URL:       server.URL,
+			Separator: " | ",
+			Request: &Request{Header: map[string]string{
+				"Authorization":   "Bearer secret",
+				"cookie":          "sid=secret",
+				"Host":            strings.TrimPrefix(server.URL, "http://"),
+				"Accept-Language": "de-DE",
+			}},
+			Elements: []Element{follow(0), follow(1)},
+		}
+		content, err := testWebsite.Scrape(nil)
+		require.NoError(t, err)
+		// the credentials and the Host are only sent to the host of the parent
+		assert.Equal(t, "Bearer secret sid=secret | "+strings.TrimPrefix(other.URL, "http://")+"   de-DE", content)
+	}
+	testMap["fileURL"] = func(t *testing.T) {
+		_, err := fetchRequest(context.Background(), FileFetcher{}, "file:///dev/null", &Request{Method: "POST"})
+		require.Error(t, err)
+		assert.Equal(t, ErrInvalidConfig, int(err.(Error).ErrType))
+	}
+
+	for testName, testFunc := range testMap {
+		t.Run(testName, testFunc)
+	}
+}
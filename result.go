@@ -0,0 +1,112 @@
+package scraper
+
+import "encoding/json"
+
+// ElementResult defines the data structure for the scraped content of a single Element
+type ElementResult struct {
+	// Name is the Name of the Element
+	Name  string `json:"name,omitempty"`
+	Value string `json:"value"`
+	// URL is the URL of the website the element was found on
+	URL string `json:"url"`
+	// Matches is the number of nodes matching the element
+	Matches int `json:"matches,omitempty"`
+	// Followed contains the result of the followed website, if ContentIsFollowURL is set
+	Followed *Result `json:"followed,omitempty"`
+	// Items contains the result of every scraped node, if the element scrapes multiple nodes
+	Items []ElementResult `json:"items,omitempty"`
+	// Elements contains the results of the child elements found inside of the scraped node
+	Elements []ElementResult `json:"elements,omitempty"`
+	// Err contains the error which occurred while scraping the element
+	Err error `json:"-"`
+}
+
+// MarshalJSON encodes r as JSON, including the msg of Err as "error"
+func (r ElementResult) MarshalJSON() ([]byte, error) {
+	type elementResult ElementResult
+	var msg string
+	if r.Err != nil {
+		msg = r.Err.Error()
+	}
+	return json.Marshal(struct {
+		elementResult
+		Error string `json:"error,omitempty"`
+	}{elementResult(r), msg})
+}
+
+// Result defines the data structure for the result of scraping a Website
+type Result struct {
+	// URL is the final URL of the scraped website
+	URL string `json:"url"`
+	// Attempts is the number of attempts needed for fetching the website, see RetryPolicy
+	Attempts int             `json:"attempts,omitempty"`
+	Elements []ElementResult `json:"elements"`
+
+	separator string
+}
+
+// String returns the values of all elements of r, each separated by the Separator of the scraped website
+func (r *Result) String() string {
+	var str string
+	for k, el := range r.Elements {
+		str += el.Value
+		if k != len(r.Elements)-1 {
+			str += r.separator
+		}
+	}
+	return str
+}
+
+// Map returns the results of all elements having a Name, keyed by the Name
+func (r *Result) Map() map[string]ElementResult {
+	return mapByName(r.Elements)
+}
+
+// Map returns the results of all child elements having a Name, keyed by the Name
+func (r *ElementResult) Map() map[string]ElementResult {
+	return mapByName(r.Elements)
+}
+
+// mapByName returns all results of elements having a Name, keyed by the Name
+func mapByName(results []ElementResult) map[string]ElementResult {
+	elements := make(map[string]ElementResult, 0)
+	for _, el := range results {
+		if el.Name != "" {
+			elements[el.Name] = el
+		}
+	}
+	return elements
+}
+
+// Err returns the first error of all elements of r, including the elements of followed websites
+func (r *Result) Err() error {
+	for _, el := range r.Elements {
+		if err := el.err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// err returns the error of r, or the first error of its followed website or its items
+func (r *ElementResult) err() error {
+	if r.Err != nil {
+		return r.Err
+	}
+	if r.Followed != nil {
+		if err := r.Followed.Err(); err != nil {
+			return err
+		}
+	}
+	for _, item := range r.Items {
+		if err := item.err(); err != nil {
+			return err
+		}
+	}
+	for _, el := range r.Elements {
+		if err := el.err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
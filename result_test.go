@@ -0,0 +1,456 @@
+package scraper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrapeResult(t *testing.T) {
+	testMap := make(map[string]func(t *testing.T), 0)
+
+	testWebsite := Website{
+		URL:       "https://example.com",
+		Separator: ", ",
+		Fetcher: mapFetcher{
+			"https://example.com":                  testHTML,
+			"https://wikipedia.com/wiki/Wikipedia": `<h1 id="firstHeading">Wikipedia</h1><p>Free encyclopedia</p>`,
+		},
+		Elements: []Element{
+			{
+				Name: "duplicate",
+				HtmlElement: HtmlElement{
+					Typ: "div",
+					Tags: []Tag{
+						{
+							Typ:   "class",
+							Value: "hasDuplicate",
+						},
+					},
+				},
+				Index: 1,
+			},
+			{
+				HtmlElement: HtmlElement{
+					Typ: "p",
+					Tags: []Tag{
+						{
+							Typ:   "id",
+							Value: "singleElement_OneTag",
+						},
+					},
+				},
+			},
+			{
+				Name: "wikipedia",
+				HtmlElement: HtmlElement{
+					Typ: "a",
+					Tags: []Tag{
+						{
+							Typ:   "id",
+							Value: "websiteLink",
+						},
+					},
+				},
+				ContentIsFollowURL: &Website{
+					Separator: " - ",
+					Elements: []Element{
+						{
+							HtmlElement: HtmlElement{
+								Typ: "h1",
+							},
+						},
+						{
+							HtmlElement: HtmlElement{
+								Typ: "p",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	testMap["elementResults"] = func(t *testing.T) {
+		res, err := testWebsite.ScrapeResult(nil)
+		require.NoError(t, err)
+		require.NoError(t, res.Err())
+		assert.Equal(t, "https://example.com", res.URL)
+		require.Equal(t, 3, len(res.Elements))
+
+		assert.Equal(t, ElementResult{
+			Name:    "duplicate",
+			Value:   "This is the second element of the duplicate",
+			URL:     "https://example.com",
+			Matches: 2,
+		}, res.Elements[0])
+		assert.Equal(t, "This is the single element with one tag", res.Elements[1].Value)
+		assert.Equal(t, 1, res.Elements[1].Matches)
+
+		followed := res.Elements[2]
+		assert.Equal(t, "Wikipedia - Free encyclopedia", followed.Value)
+		require.NotNil(t, followed.Followed)
+		assert.Equal(t, "https://wikipedia.com/wiki/Wikipedia", followed.Followed.URL)
+		assert.Equal(t, "Wikipedia", followed.Followed.Elements[0].Value)
+	}
+	testMap["map"] = func(t *testing.T) {
+		res, err := testWebsite.ScrapeResult(nil)
+		require.NoError(t, err)
+
+		elements := res.Map()
+		assert.Equal(t, 2, len(elements))
+		assert.Equal(t, "This is the second element of the duplicate", elements["duplicate"].Value)
+		assert.Equal(t, "Wikipedia - Free encyclopedia", elements["wikipedia"].Value)
+	}
+	testMap["string"] = func(t *testing.T) {
+		content, err := testWebsite.Scrape(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "This is the second element of the duplicate, This is the single element with one tag, Wikipedia - Free encyclopedia", content)
+	}
+	testMap["elementError"] = func(t *testing.T) {
+		website := testWebsite
+		website.Elements = append([]Element{
+			{
+				Name: "missing",
+				HtmlElement: HtmlElement{
+					Typ: "table",
+				},
+			},
+		}, testWebsite.Elements...)
+
+		res, err := website.ScrapeResult(nil)
+		require.NoError(t, err)
+		require.Equal(t, 4, len(res.Elements))
+		assert.Equal(t, "missing table in the node tree", res.Elements[0].Err.Error())
+		assert.Equal(t, 0, res.Elements[0].Matches)
+		assert.Equal(t, "This is the second element of the duplicate", res.Elements[1].Value)
+		assert.Equal(t, res.Elements[0].Err, res.Err())
+
+		_, err = website.Scrape(nil)
+		assert.Equal(t, res.Elements[0].Err, err)
+	}
+	testMap["followedElementError"] = func(t *testing.T) {
+		website := testWebsite
+		website.Elements = []Element{testWebsite.Elements[2]}
+		followed := *website.Elements[0].ContentIsFollowURL
+		followed.Elements = []Element{{HtmlElement: HtmlElement{Typ: "table"}}}
+		website.Elements[0].ContentIsFollowURL = &followed
+
+		res, err := website.ScrapeResult(nil)
+		require.NoError(t, err)
+		assert.NoError(t, res.Elements[0].Err)
+		require.Error(t, res.Err())
+		assert.Equal(t, "missing table in the node tree", res.Err().Error())
+	}
+
+	for testName, testFunc := range testMap {
+		t.Run(testName, testFunc)
+	}
+}
+
+func TestScrapeResultMultipleNodes(t *testing.T) {
+	testMap := make(map[string]func(t *testing.T), 0)
+
+	listHTML := `
+	<ul>
+		<li><a href="/one">One</a></li>
+		<li><a href="/two">Two</a></li>
+		<li><a href="/three">Three</a></li>
+		<li><a>Four</a></li>
+	</ul>`
+	fetcher := mapFetcher{
+		"https://example.com/list":  listHTML,
+		"https://example.com/one":   `<h1>First page</h1>`,
+		"https://example.com/two":   `<h1>Second page</h1>`,
+		"https://example.com/three": `<h1>Third page</h1>`,
+	}
+	listWebsite := func(el Element) Website {
+		return Website{
+			URL:       "https://example.com/list",
+			Separator: "|",
+			Fetcher:   fetcher,
+			Elements:  []Element{el},
+		}
+	}
+
+	testMap["all"] = func(t *testing.T) {
+		testWebsite := listWebsite(Element{
+			Name: "items",
+			HtmlElement: HtmlElement{
+				Typ: "li",
+			},
+			Settings: Settings{
+				FormatSettings: FormatSettings{
+					AddBefore: "- ",
+				},
+			},
+			All: true,
+		})
+
+		res, err := testWebsite.ScrapeResult(nil)
+		require.NoError(t, err)
+		require.NoError(t, res.Err())
+		el := res.Elements[0]
+		assert.Equal(t, 4, el.Matches)
+		require.Equal(t, 4, len(el.Items))
+		assert.Equal(t, "- One", el.Items[0].Value)
+		assert.Equal(t, "- Four", el.Items[3].Value)
+		assert.Equal(t, "https://example.com/list", el.Items[3].URL)
+		assert.Equal(t, "- One|- Two|- Three|- Four", el.Value)
+		assert.Equal(t, "- One|- Two|- Three|- Four", res.String())
+	}
+	testMap["allWithoutSeparator"] = func(t *testing.T) {
+		testWebsite := listWebsite(Element{HtmlElement: HtmlElement{Typ: "li"}, All: true})
+		testWebsite.Separator = ""
+
+		// the items are separated by new lines like the ones of ScrapeTreeForElement
+		content, err := testWebsite.Scrape(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "One\nTwo\nThree\nFour", content)
+		nodeTree, err := GetHTMLNode(listHTML)
+		require.NoError(t, err)
+		treeContent, err := testWebsite.Elements[0].ScrapeTreeForElement(nodeTree)
+		require.NoError(t, err)
+		assert.Equal(t, content, treeContent)
+	}
+	testMap["slice"] = func(t *testing.T) {
+		testCases := map[string][]string{
+			"1:":  {"Two", "Three", "Four"},
+			":-2": {"One", "Two"},
+			"-1:": {"Four"},
+			"5:":  {},
+		}
+		for slice, expected := range testCases {
+			testWebsite := listWebsite(Element{
+				HtmlElement: HtmlElement{
+					Typ: "li",
+				},
+				Slice: slice,
+			})
+
+			res, err := testWebsite.ScrapeResult(nil)
+			require.NoError(t, err)
+			require.NoError(t, res.Err())
+			values := []string{}
+			for _, item := range res.Elements[0].Items {
+				values = append(values, item.Value)
+			}
+			assert.Equal(t, expected, values, slice)
+		}
+	}
+	testMap["followEveryItem"] = func(t *testing.T) {
+		testWebsite := listWebsite(Element{
+			HtmlElement: HtmlElement{
+				Typ: "a",
+			},
+			Settings: Settings{
+				Attribute: "href",
+			},
+			Slice: ":3",
+			ContentIsFollowURL: &Website{
+				Elements: []Element{
+					{
+						HtmlElement: HtmlElement{
+							Typ: "h1",
+						},
+					},
+				},
+			},
+		})
+
+		res, err := testWebsite.ScrapeResult(nil)
+		require.NoError(t, err)
+		require.NoError(t, res.Err())
+		items := res.Elements[0].Items
+		require.Equal(t, 3, len(items))
+		assert.Equal(t, "https://example.com/two", items[1].Followed.URL)
+		assert.Equal(t, "Second page", items[1].Value)
+		assert.Equal(t, "First page|Second page|Third page", res.String())
+	}
+	testMap["itemError"] = func(t *testing.T) {
+		testWebsite := listWebsite(Element{
+			HtmlElement: HtmlElement{
+				Typ: "a",
+			},
+			Settings: Settings{
+				Attribute: "href",
+			},
+			All: true,
+		})
+
+		res, err := testWebsite.ScrapeResult(nil)
+		require.NoError(t, err)
+		items := res.Elements[0].Items
+		require.Equal(t, 4, len(items))
+		assert.Equal(t, "/three", items[2].Value)
+		require.Error(t, items[3].Err)
+		assert.Equal(t, items[3].Err, res.Err())
+
+		_, err = testWebsite.Scrape(nil)
+		assert.Equal(t, "missing attribute href of a", err.Error())
+	}
+	testMap["invalidSlice"] = func(t *testing.T) {
+		testWebsite := listWebsite(Element{
+			HtmlElement: HtmlElement{
+				Typ: "li",
+			},
+			Slice: "one:",
+		})
+
+		res, err := testWebsite.ScrapeResult(nil)
+		require.NoError(t, err)
+		require.Error(t, res.Elements[0].Err)
+		assert.Equal(t, ErrInvalidIndex, int(res.Elements[0].Err.(Error).ErrType))
+	}
+	testMap["scrapeTreeForElement"] = func(t *testing.T) {
+		nodeTree, err := GetHTMLNode(listHTML)
+		require.NoError(t, err)
+		testElement := Element{
+			HtmlElement: HtmlElement{
+				Typ: "li",
+			},
+			Slice: "-2:",
+		}
+
+		content, err := testElement.ScrapeTreeForElement(nodeTree)
+		require.NoError(t, err)
+		assert.Equal(t, "Three\nFour", content)
+	}
+
+	for testName, testFunc := range testMap {
+		t.Run(testName, testFunc)
+	}
+}
+
+func TestScrapeResultNestedElements(t *testing.T) {
+	testMap := make(map[string]func(t *testing.T), 0)
+
+	testWebsite := Website{
+		URL:       "https://example.com/products",
+		Separator: ";",
+		Fetcher: mapFetcher{
+			"https://example.com/products": testSelectorHTML,
+			"https://example.com/first":    `<p class="stock">In stock</p>`,
+		},
+		Elements: []Element{
+			{
+				Name: "products",
+				HtmlElement: HtmlElement{
+					Selector: "div.product",
+				},
+				All: true,
+				Elements: []Element{
+					{
+						Name: "title",
+						HtmlElement: HtmlElement{
+							Typ: "h2",
+						},
+					},
+					{
+						Name: "price",
+						HtmlElement: HtmlElement{
+							Selector: "span.price",
+						},
+						Index: Last,
+					},
+				},
+			},
+		},
+	}
+
+	testMap["records"] = func(t *testing.T) {
+		res, err := testWebsite.ScrapeResult(nil)
+		require.NoError(t, err)
+		require.NoError(t, res.Err())
+
+		products := res.Map()["products"]
+		require.Equal(t, 2, len(products.Items))
+
+		first := products.Items[0].Map()
+		assert.Equal(t, "First product", first["title"].Value)
+		assert.Equal(t, "8", first["price"].Value)
+		assert.Equal(t, 2, first["price"].Matches)
+
+		second := products.Items[1].Map()
+		assert.Equal(t, "Second product", second["title"].Value)
+		assert.Equal(t, "20", second["price"].Value)
+		assert.Equal(t, 1, second["price"].Matches)
+
+		assert.Equal(t, "First product;8;Second product;20", res.String())
+	}
+	testMap["singleRecord"] = func(t *testing.T) {
+		website := testWebsite
+		product := website.Elements[0]
+		product.All, product.Index = false, 1
+		website.Elements = []Element{product}
+
+		res, err := website.ScrapeResult(nil)
+		require.NoError(t, err)
+		require.NoError(t, res.Err())
+		record := res.Elements[0]
+		assert.Nil(t, record.Items)
+		assert.Equal(t, "Second product", record.Map()["title"].Value)
+		assert.Equal(t, "Second product;20", record.Value)
+	}
+	testMap["sameTag"] = func(t *testing.T) {
+		website := Website{
+			URL:       "https://example.com/cards",
+			Separator: ";",
+			Fetcher: mapFetcher{"https://example.com/cards": `
+				<div class="card"><h2>Title</h2><div>inner1</div></div>
+				<div class="card"><h2>T2</h2><div>inner2</div></div>`},
+			Elements: []Element{
+				{
+					HtmlElement: HtmlElement{Typ: "div", Tags: []Tag{{Typ: "class", Value: "card"}}},
+					All:         true,
+					Elements: []Element{
+						{HtmlElement: HtmlElement{Typ: "div"}},
+						{HtmlElement: HtmlElement{Selector: "div"}},
+						{HtmlElement: HtmlElement{Selector: "div.card > div"}},
+					},
+				},
+			},
+		}
+
+		// the child elements match the descendants of every card, but not the card itself
+		res, err := website.ScrapeResult(nil)
+		require.NoError(t, err)
+		require.NoError(t, res.Err())
+		assert.Equal(t, "inner1;inner1;inner1;inner2;inner2;inner2", res.String())
+	}
+	testMap["nestedFollowURL"] = func(t *testing.T) {
+		website := testWebsite
+		product := website.Elements[0]
+		product.Elements = append(product.Elements, Element{
+			Name: "stock",
+			HtmlElement: HtmlElement{
+				Typ: "a",
+			},
+			Settings: Settings{
+				Attribute: "href",
+			},
+			ContentIsFollowURL: &Website{
+				Elements: []Element{
+					{
+						HtmlElement: HtmlElement{
+							Selector: ".stock",
+						},
+					},
+				},
+			},
+		})
+		website.Elements = []Element{product}
+
+		res, err := website.ScrapeResult(nil)
+		require.NoError(t, err)
+		items := res.Elements[0].Items
+		assert.Equal(t, "In stock", items[0].Map()["stock"].Value)
+		require.Error(t, items[1].Map()["stock"].Err)
+		assert.Equal(t, "no page for https://example.com/second.pdf", res.Err().Error())
+	}
+
+	for testName, testFunc := range testMap {
+		t.Run(testName, testFunc)
+	}
+}
@@ -0,0 +1,194 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	// defaultBaseDelay is the BaseDelay of a RetryPolicy without one
+	defaultBaseDelay = 500 * time.Millisecond
+	// defaultMaxDelay is the MaxDelay of a RetryPolicy without one
+	defaultMaxDelay = 30 * time.Second
+)
+
+// defaultRetryStatusCodes are the RetryStatusCodes of a RetryPolicy without any
+var defaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// randFloat returns a random number in [0, 1) for the jitter of delays
+var randFloat = rand.Float64
+
+// RetryPolicy defines the data structure for retrying failed fetches of a website, which are retried for
+// transient network errors (e.g. connection resets and timeouts) and for the RetryStatusCodes
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts of a fetch including the first one, values below 2 disable retries
+	MaxAttempts int `json:"maxAttempts" yaml:"maxAttempts"`
+	// BaseDelay is the delay before the first retry, which doubles for every further retry, defaults to 500ms
+	BaseDelay time.Duration `json:"baseDelay" yaml:"baseDelay"`
+	// MaxDelay caps the delay between two attempts, defaults to 30s, a fetch is not retried
+	// if its response asks for waiting longer than MaxDelay using a Retry-After header
+	MaxDelay time.Duration `json:"maxDelay" yaml:"maxDelay"`
+	// Jitter shortens every delay by a random fraction of up to Jitter (0 to 1), spreading retries of concurrent scrapes
+	Jitter float64 `json:"jitter" yaml:"jitter"`
+	// RetryStatusCodes lists the status codes to be retried, defaults to 429, 500, 502, 503 and 504
+	RetryStatusCodes []int `json:"retryStatusCodes" yaml:"retryStatusCodes"`
+	// RetryNonIdempotent retries requests using a method which is not idempotent (e.g. POST) as well,
+	// which could take effect more than once
+	RetryNonIdempotent bool `json:"retryNonIdempotent" yaml:"retryNonIdempotent"`
+}
+
+// RetryError defines the data structure for the error of a fetch, which failed after multiple attempts
+type RetryError struct {
+	Attempts int
+	// Err is the error of the last attempt
+	Err error
+}
+
+// Error returns the error msg of a RetryError
+func (e RetryError) Error() string {
+	return e.Err.Error() + " (after " + strconv.Itoa(e.Attempts) + " attempts)"
+}
+
+// Unwrap returns the error of the last attempt
+func (e RetryError) Unwrap() error {
+	return e.Err
+}
+
+// fetchRetry fetches URL like fetch, retrying it according to policy (which may be nil),
+// returning the number of attempts made, requests which are not idempotent are only retried
+// if the policy opts in using RetryNonIdempotent
+func fetchRetry(ctx context.Context, f Fetcher, URL string, req *Request, accepted []int, policy *RetryPolicy) (*Response, int, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := fetch(ctx, f, URL, req, accepted)
+		if err == nil {
+			return resp, attempt, nil
+		}
+		if ctx.Err() != nil || (!req.idempotent() && (policy == nil || !policy.RetryNonIdempotent)) {
+			return nil, attempt, err
+		}
+
+		delay, ok := policy.delay(attempt, err)
+		if !ok {
+			if attempt > 1 {
+				err = RetryError{Attempts: attempt, Err: err}
+			}
+			return nil, attempt, err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, attempt, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// delay returns the delay before retrying a fetch, which failed with err at attempt, and whether it is retried
+func (r *RetryPolicy) delay(attempt int, err error) (time.Duration, bool) {
+	if r == nil || attempt >= r.MaxAttempts {
+		return 0, false
+	}
+
+	maxDelay := r.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultMaxDelay
+	}
+
+	var statusErr StatusError
+	if errors.As(err, &statusErr) {
+		if !containsInt(r.statusCodes(), statusErr.StatusCode) {
+			return 0, false
+		}
+		if retryAfter, ok := parseRetryAfter(statusErr.Header.Get("Retry-After")); ok {
+			return retryAfter, retryAfter <= maxDelay
+		}
+	} else if !transient(err) {
+		return 0, false
+	}
+
+	delay := r.BaseDelay
+	if delay <= 0 {
+		delay = defaultBaseDelay
+	}
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	if r.Jitter > 0 {
+		delay -= time.Duration(r.Jitter * randFloat() * float64(delay))
+	}
+	return delay, true
+}
+
+// statusCodes returns the RetryStatusCodes of r, or the default ones
+func (r *RetryPolicy) statusCodes() []int {
+	if len(r.RetryStatusCodes) > 0 {
+		return r.RetryStatusCodes
+	}
+	return defaultRetryStatusCodes
+}
+
+// transient reports whether err is a network error, which may not occur again, being a timeout, a refused
+// or reset connection or an unexpected EOF, while e.g. invalid certificates or too many redirects are permanent
+func transient(err error) bool {
+	// every error of an http.Client is a *url.Error, which is a net.Error itself
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	// the Timeout of an http.Client is a context.DeadlineExceeded as well, so only canceled fetches are excluded,
+	// while fetchRetry stops once its own ctx is done
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// parseRetryAfter parses the value of a Retry-After header, being either a number of seconds or a date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// containsInt reports whether ints contains i
+func containsInt(ints []int, i int) bool {
+	for _, v := range ints {
+		if v == i {
+			return true
+		}
+	}
+	return false
+}
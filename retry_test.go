@@ -0,0 +1,234 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	defer func(f func() float64) { randFloat = f }(randFloat)
+	randFloat = func() float64 { return 0.5 }
+
+	statusErr := func(code int, retryAfter string) error {
+		header := http.Header{}
+		if retryAfter != "" {
+			header.Set("Retry-After", retryAfter)
+		}
+		return StatusError{ErrType: ErrHTTPStatus, StatusCode: code, Header: header}
+	}
+	policy := &RetryPolicy{MaxAttempts: 6, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	jittered := &RetryPolicy{MaxAttempts: 6, BaseDelay: 100 * time.Millisecond, Jitter: 0.5}
+
+	testCases := []struct {
+		name    string
+		policy  *RetryPolicy
+		attempt int
+		err     error
+		delay   time.Duration
+		retried bool
+	}{
+		{"firstRetry", policy, 1, statusErr(http.StatusServiceUnavailable, ""), 100 * time.Millisecond, true},
+		{"exponential", policy, 3, statusErr(http.StatusServiceUnavailable, ""), 400 * time.Millisecond, true},
+		{"capped", policy, 5, statusErr(http.StatusServiceUnavailable, ""), time.Second, true},
+		{"maxAttempts", policy, 6, statusErr(http.StatusServiceUnavailable, ""), 0, false},
+		{"jitter", jittered, 1, statusErr(http.StatusServiceUnavailable, ""), 75 * time.Millisecond, true},
+		{"retryAfter", policy, 1, statusErr(http.StatusTooManyRequests, "1"), time.Second, true},
+		{"retryAfterTooLong", policy, 1, statusErr(http.StatusTooManyRequests, "60"), time.Minute, false},
+		{"notRetriedStatus", policy, 1, statusErr(http.StatusNotFound, ""), 0, false},
+		{"connectionReset", policy, 1, fmt.Errorf("read: %w", syscall.ECONNRESET), 100 * time.Millisecond, true},
+		{"permanentError", policy, 1, errors.New("unsupported protocol"), 0, false},
+		{"canceled", policy, 1, context.Canceled, 0, false},
+		{"noPolicy", nil, 1, statusErr(http.StatusServiceUnavailable, ""), 0, false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			delay, retried := tc.policy.delay(tc.attempt, tc.err)
+			assert.Equal(t, tc.retried, retried)
+			if tc.retried || tc.delay > 0 {
+				assert.Equal(t, tc.delay, delay)
+			}
+		})
+	}
+}
+
+// flakyFetcher is a Fetcher failing the first Failures[URL] fetches of a URL using Fail before serving its page
+type flakyFetcher struct {
+	pages    mapFetcher
+	failures map[string]int
+	fail     func(URL string) (*Response, error)
+}
+
+func (f *flakyFetcher) Fetch(ctx context.Context, URL string) (*Response, error) {
+	if f.failures[URL] > 0 {
+		f.failures[URL]--
+		return f.fail(URL)
+	}
+	return f.pages.Fetch(ctx, URL)
+}
+
+func TestScrapeRetry(t *testing.T) {
+	testMap := make(map[string]func(t *testing.T), 0)
+
+	unavailable := func(URL string) (*Response, error) {
+		return &Response{URL: URL, StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}, nil
+	}
+	retry := &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	testMap["mainPageAndFollowed"] = func(t *testing.T) {
+		fetcher := &flakyFetcher{
+			pages: mapFetcher{
+				"https://example.com":      `<a href="/next">Next</a>`,
+				"https://example.com/next": `<h1>Next</h1>`,
+			},
+			failures: map[string]int{"https://example.com": 2, "https://example.com/next": 1},
+			fail: func(URL string) (*Response, error) {
+				if URL == "https://example.com" {
+					return unavailable(URL)
+				}
+				return nil, fmt.Errorf("read: %w", syscall.ECONNRESET)
+			},
+		}
+		testWebsite := Website{
+			URL:     "https://example.com",
+			Fetcher: fetcher,
+			Retry:   retry,
+			Elements: []Element{
+				{
+					HtmlElement:        HtmlElement{Typ: "a"},
+					Settings:           Settings{Attribute: "href"},
+					ContentIsFollowURL: &Website{Elements: []Element{{HtmlElement: HtmlElement{Typ: "h1"}}}},
+				},
+			},
+		}
+
+		res, err := testWebsite.ScrapeResult(nil)
+		require.NoError(t, err)
+		require.NoError(t, res.Err())
+		assert.Equal(t, "Next", res.String())
+		assert.Equal(t, 3, res.Attempts)
+		assert.Equal(t, 2, res.Elements[0].Followed.Attempts)
+	}
+	testMap["exhausted"] = func(t *testing.T) {
+		testWebsite := Website{
+			URL:      "https://example.com",
+			Fetcher:  &flakyFetcher{failures: map[string]int{"https://example.com": 5}, fail: unavailable},
+			Retry:    retry,
+			Elements: []Element{{HtmlElement: HtmlElement{Typ: "h1"}}},
+		}
+
+		_, err := testWebsite.Scrape(nil)
+		var retryErr RetryError
+		require.True(t, errors.As(err, &retryErr))
+		assert.Equal(t, 3, retryErr.Attempts)
+		typ, _ := ErrTypeOf(err)
+		assert.Equal(t, ErrHTTPStatus, int(typ))
+		assert.Equal(t, "unexpected status code 503 for https://example.com (after 3 attempts)", err.Error())
+	}
+	testMap["canceledWhileWaiting"] = func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		testWebsite := Website{
+			URL:      "https://example.com",
+			Fetcher:  &flakyFetcher{failures: map[string]int{"https://example.com": 5}, fail: unavailable},
+			Retry:    &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour},
+			Elements: []Element{{HtmlElement: HtmlElement{Typ: "h1"}}},
+		}
+
+		_, err := testWebsite.ScrapeContext(ctx, nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+	testMap["httpFetcherErrors"] = func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, r.URL.Path, http.StatusFound)
+		}))
+		defer server.Close()
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+
+		timeout := &HTTPFetcher{Client: &http.Client{Timeout: time.Nanosecond}}
+		testCases := []struct {
+			name     string
+			URL      string
+			fetcher  Fetcher
+			attempts int
+		}{
+			{"unsupportedScheme", "gopher://example.com", nil, 1},
+			{"tooManyRedirects", server.URL + "/loop", nil, 1},
+			{"invalidCertificate", strings.Replace(server.URL, "http:", "https:", 1), nil, 1},
+			{"connectionRefused", closed.URL, nil, 3},
+			{"timeout", server.URL, timeout, 3},
+		}
+		for _, tc := range testCases {
+			testWebsite := Website{URL: tc.URL, Fetcher: tc.fetcher, Retry: retry, Elements: []Element{{HtmlElement: HtmlElement{Typ: "h1"}}}}
+			res, err := testWebsite.ScrapeResult(nil)
+			require.Error(t, err, tc.name)
+			assert.Nil(t, res, tc.name)
+
+			attempts := 1
+			var retryErr RetryError
+			if errors.As(err, &retryErr) {
+				attempts = retryErr.Attempts
+			}
+			assert.Equal(t, tc.attempts, attempts, tc.name+": "+err.Error())
+		}
+	}
+	testMap["nonIdempotent"] = func(t *testing.T) {
+		var posts int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				posts++
+			}
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		testWebsite := Website{
+			URL:      server.URL,
+			Request:  &Request{Method: "post", Body: "order=1"},
+			Retry:    &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			Elements: []Element{{HtmlElement: HtmlElement{Typ: "h1"}}},
+		}
+		_, err := testWebsite.Scrape(nil)
+		require.Error(t, err)
+		assert.Equal(t, 1, posts)
+
+		testWebsite.Retry.RetryNonIdempotent = true
+		_, err = testWebsite.Scrape(nil)
+		require.Error(t, err)
+		assert.Equal(t, 4, posts)
+
+		// idempotent methods are retried anyway
+		testWebsite.Retry.RetryNonIdempotent = false
+		testWebsite.Request = &Request{Method: "PUT"}
+		res, err := testWebsite.ScrapeResult(nil)
+		require.Error(t, err)
+		assert.Nil(t, res)
+		var retryErr RetryError
+		require.True(t, errors.As(err, &retryErr))
+		assert.Equal(t, 3, retryErr.Attempts)
+	}
+	testMap["fromConfig"] = func(t *testing.T) {
+		w, err := ParseWebsite(strings.NewReader("url: https://example.com\nretry: {maxAttempts: 4, baseDelay: 250ms, jitter: 0.2}\n"))
+		require.NoError(t, err)
+		assert.Equal(t, &RetryPolicy{MaxAttempts: 4, BaseDelay: 250 * time.Millisecond, Jitter: 0.2}, w.Retry)
+
+		w.Retry.Jitter = 2
+		assert.Error(t, w.Validate())
+	}
+
+	for testName, testFunc := range testMap {
+		t.Run(testName, testFunc)
+	}
+}
@@ -0,0 +1,217 @@
+package scraper
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultUserAgent is the user agent a PoliteFetcher identifies itself with if Politeness.UserAgent is not set
+const DefaultUserAgent = "goScraper"
+
+// RobotsTxt defines the data structure for a parsed robots.txt file
+type RobotsTxt struct {
+	// Sitemaps contains the URLs of all Sitemap lines
+	Sitemaps []string
+	groups   []robotsGroup
+}
+
+// robotsGroup defines the data structure for a group of rules applying to the user agents of the group
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// robotsRule defines the data structure for a single Allow or Disallow line
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// ParseRobotsTxt parses the robots.txt data, ignoring lines which could not be parsed
+func ParseRobotsTxt(data []byte) *RobotsTxt {
+	r := &RobotsTxt{}
+	var group *robotsGroup
+	// inAgents reports whether the previous line was a user-agent line, which start a new group otherwise
+	inAgents := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(bytes.TrimPrefix(data, utf8BOM)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				r.groups = append(r.groups, robotsGroup{})
+				group = &r.groups[len(r.groups)-1]
+			}
+			group.agents = append(group.agents, strings.ToLower(value))
+			inAgents = true
+			continue
+		case "allow", "disallow":
+			// an empty Disallow allows everything, which is the default anyway
+			if group != nil && value != "" {
+				group.rules = append(group.rules, robotsRule{allow: key == "allow", pattern: value})
+			}
+		case "crawl-delay":
+			if secs, err := strconv.ParseFloat(value, 64); group != nil && err == nil && secs >= 0 {
+				group.crawlDelay = time.Duration(secs * float64(time.Second))
+			}
+		case "sitemap":
+			// sitemaps do not belong to a group
+			if value != "" {
+				r.Sitemaps = append(r.Sitemaps, value)
+			}
+			continue
+		}
+		inAgents = false
+	}
+	return r
+}
+
+// Allowed reports whether userAgent may fetch URL (or a path like /page?id=1)
+//
+// The groups of userAgent (matched case-insensitively by the product token, e.g. goScraper of goScraper/1.0)
+// apply, falling back to the group of *. The Allow or Disallow rule with the longest pattern matching the
+// path wins, Allow winning ties, where * matches any characters and a trailing $ the end of the path.
+func (r *RobotsTxt) Allowed(userAgent, URL string) bool {
+	path := "/"
+	if u, err := url.Parse(URL); err == nil {
+		if path = u.EscapedPath(); path == "" {
+			path = "/"
+		}
+		if u.RawQuery != "" {
+			path += "?" + u.RawQuery
+		}
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+
+	allowed, longest := true, -1
+	for _, g := range r.groupsOf(userAgent) {
+		for _, rule := range g.rules {
+			if !matchRobotsPattern(rule.pattern, path) {
+				continue
+			}
+			switch n := len(rule.pattern); {
+			case n > longest:
+				allowed, longest = rule.allow, n
+			case n == longest:
+				allowed = allowed || rule.allow
+			}
+		}
+	}
+	return allowed
+}
+
+// CrawlDelay returns the Crawl-delay of the groups of userAgent, or 0 if none is set
+func (r *RobotsTxt) CrawlDelay(userAgent string) time.Duration {
+	var delay time.Duration
+	for _, g := range r.groupsOf(userAgent) {
+		if g.crawlDelay > delay {
+			delay = g.crawlDelay
+		}
+	}
+	return delay
+}
+
+// groupsOf returns the groups applying to userAgent, which are the groups of * if none names userAgent
+func (r *RobotsTxt) groupsOf(userAgent string) []robotsGroup {
+	token := strings.ToLower(userAgent)
+	if i := strings.IndexAny(token, "/ "); i >= 0 {
+		token = token[:i]
+	}
+
+	var named, wildcard []robotsGroup
+	for _, g := range r.groups {
+		for _, agent := range g.agents {
+			if agent == token {
+				named = append(named, g)
+				break
+			}
+			if agent == "*" {
+				wildcard = append(wildcard, g)
+				break
+			}
+		}
+	}
+	if len(named) > 0 {
+		return named
+	}
+	return wildcard
+}
+
+// matchRobotsPattern reports whether the path matches the pattern of an Allow or Disallow rule
+func matchRobotsPattern(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = pattern[:len(pattern)-1]
+	}
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	for k, part := range parts[1:] {
+		if anchored && k == len(parts)-2 {
+			return strings.HasSuffix(rest, part)
+		}
+		i := strings.Index(rest, part)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+	return !anchored || rest == ""
+}
+
+// robotsTxt returns the cached robots.txt of the host of u, fetching it once using fetcher and identifying as agent
+//
+// A missing robots.txt (status code 4xx) allows everything, while a robots.txt responding with
+// any other error status code disallows everything until it has been fetched successfully.
+func (f *PoliteFetcher) robotsTxt(ctx context.Context, fetcher Fetcher, h *hostLimiter, u *url.URL, agent string) (*RobotsTxt, error) {
+	h.robotsMu.Lock()
+	defer h.robotsMu.Unlock()
+	if h.robots != nil {
+		return h.robots, nil
+	}
+
+	if err := f.wait(ctx, h, f.MinDelay); err != nil {
+		return nil, err
+	}
+	robotsURL := (&url.URL{Scheme: u.Scheme, User: u.User, Host: u.Host, Path: "/robots.txt"}).String()
+	var req *Request
+	if _, ok := fetcher.(RequestFetcher); ok {
+		req = req.withUserAgent(agent)
+	}
+	resp, err := fetchRequest(ctx, fetcher, robotsURL, req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		h.robots = ParseRobotsTxt(resp.Body)
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		h.robots = &RobotsTxt{}
+	default:
+		return nil, newErr(ErrDisallowed, robotsURL+" is unavailable (status code "+strconv.Itoa(resp.StatusCode)+" "+http.StatusText(resp.StatusCode)+")")
+	}
+	return h.robots, nil
+}
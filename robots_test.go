@@ -0,0 +1,206 @@
+package scraper
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRobotsTxt = `# robots.txt of example.com
+User-agent: *
+Disallow: /private/
+Allow: /private/public.html
+Crawl-delay: 0.05
+
+User-agent: goScraper
+User-agent: OtherBot
+Disallow: /*.pdf$
+Disallow: /search?
+Allow: /search?q=fruit
+Disallow: /tmp # temporary files
+Disallow:
+
+Sitemap: https://example.com/sitemap.xml
+`
+
+func TestRobotsTxt(t *testing.T) {
+	robots := ParseRobotsTxt([]byte(testRobotsTxt))
+	assert.Equal(t, []string{"https://example.com/sitemap.xml"}, robots.Sitemaps)
+	assert.Equal(t, 50*time.Millisecond, robots.CrawlDelay("SomeBot/2.1"))
+	assert.Equal(t, time.Duration(0), robots.CrawlDelay("goScraper/1.0"))
+
+	tests := []struct {
+		userAgent string
+		URL       string
+		allowed   bool
+	}{
+		{"SomeBot", "https://example.com/", true},
+		{"SomeBot", "https://example.com/private/page.html", false},
+		{"SomeBot", "https://example.com/private/public.html", true},
+		{"SomeBot", "https://example.com/paper.pdf", true},
+		{"goscraper/1.0", "https://example.com/private/page.html", true},
+		{"goScraper", "https://example.com/paper.pdf", false},
+		{"goScraper", "https://example.com/paper.pdf?download=1", true},
+		{"goScraper", "https://example.com/docs/paper.pdf", false},
+		{"goScraper", "https://example.com/search?q=vegetables", false},
+		{"goScraper", "https://example.com/search?q=fruit", true},
+		{"goScraper", "https://example.com/tmp/file", false},
+		{"OtherBot", "https://example.com/tmpl", false},
+		{"OtherBot", "/robots.txt", true},
+		{"OtherBot", "/search", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.allowed, robots.Allowed(tt.userAgent, tt.URL), tt.userAgent+" "+tt.URL)
+	}
+}
+
+func TestMatchRobotsPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"/", "/anything", true},
+		{"/fish", "/fish.html", true},
+		{"/fish", "/Fish.html", false},
+		{"/fish$", "/fish", true},
+		{"/fish$", "/fish/", false},
+		{"/*.php", "/folder/index.php?x=1", true},
+		{"/*.php$", "/folder/index.php?x=1", false},
+		{"/*.php$", "/index.php", true},
+		{"/a*b*c$", "/abcbc", true},
+		{"/a*b*c$", "/abcb", false},
+		{"*", "/", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.match, matchRobotsPattern(tt.pattern, tt.path), tt.pattern+" "+tt.path)
+	}
+}
+
+func TestPoliteFetcherRobots(t *testing.T) {
+	testMap := make(map[string]func(t *testing.T), 0)
+
+	// robotsFetcher serves robots with the status code status and counts the requests of every URL
+	robotsFetcher := func(status int, robots string, requests map[string]*int32) Fetcher {
+		for _, URL := range []string{"https://example.com/robots.txt", "https://example.com/page", "https://example.com/private/page"} {
+			requests[URL] = new(int32)
+		}
+		return FetcherFunc(func(ctx context.Context, URL string) (*Response, error) {
+			atomic.AddInt32(requests[URL], 1)
+			if strings.HasSuffix(URL, "/robots.txt") {
+				return &Response{URL: URL, StatusCode: status, Body: []byte(robots)}, nil
+			}
+			return &Response{URL: URL, StatusCode: http.StatusOK, Body: []byte("<h1>Page</h1>")}, nil
+		})
+	}
+
+	testMap["disallowed"] = func(t *testing.T) {
+		requests := make(map[string]*int32)
+		f := NewPoliteFetcher(robotsFetcher(http.StatusOK, testRobotsTxt, requests), Politeness{Robots: true, UserAgent: "SomeBot"})
+
+		_, err := f.Fetch(context.Background(), "https://example.com/private/page")
+		require.Error(t, err)
+		typ, ok := ErrTypeOf(err)
+		assert.True(t, ok)
+		assert.Equal(t, ErrDisallowed, int(typ))
+		assert.Equal(t, int32(0), *requests["https://example.com/private/page"])
+
+		_, err = f.Fetch(context.Background(), "https://example.com/page")
+		assert.NoError(t, err)
+		// robots.txt is cached per host
+		assert.Equal(t, int32(1), *requests["https://example.com/robots.txt"])
+	}
+	testMap["crawlDelay"] = func(t *testing.T) {
+		requests := make(map[string]*int32)
+		f := NewPoliteFetcher(robotsFetcher(http.StatusOK, testRobotsTxt, requests), Politeness{Robots: true, UserAgent: "SomeBot"})
+		start := time.Now()
+		fetchAll(t, f, 3, "https://example.com/page")
+		// the three pages are spaced by the crawl delay after robots.txt
+		assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+		assert.Equal(t, int32(3), *requests["https://example.com/page"])
+	}
+	testMap["missingRobotsTxt"] = func(t *testing.T) {
+		requests := make(map[string]*int32)
+		f := NewPoliteFetcher(robotsFetcher(http.StatusNotFound, "", requests), Politeness{Robots: true})
+		_, err := f.Fetch(context.Background(), "https://example.com/private/page")
+		assert.NoError(t, err)
+	}
+	testMap["unavailableRobotsTxt"] = func(t *testing.T) {
+		requests := make(map[string]*int32)
+		f := NewPoliteFetcher(robotsFetcher(http.StatusServiceUnavailable, "", requests), Politeness{Robots: true})
+		for i := 0; i < 2; i++ {
+			_, err := f.Fetch(context.Background(), "https://example.com/page")
+			typ, _ := ErrTypeOf(err)
+			assert.Equal(t, ErrDisallowed, int(typ))
+		}
+		// an unavailable robots.txt is fetched again
+		assert.Equal(t, int32(2), *requests["https://example.com/robots.txt"])
+		assert.Equal(t, int32(0), *requests["https://example.com/page"])
+	}
+	testMap["userAgent"] = func(t *testing.T) {
+		var mu sync.Mutex
+		agents := make(map[string]string)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			agents[r.URL.Path] = r.Header.Get("User-Agent")
+			mu.Unlock()
+			if r.URL.Path == "/robots.txt" {
+				io.WriteString(w, testRobotsTxt)
+				return
+			}
+			io.WriteString(w, "<h1>Page</h1>")
+		}))
+		defer server.Close()
+
+		// the rules of goScraper allow the page, while the ones of * do not
+		f := NewPoliteFetcher(nil, Politeness{Robots: true})
+		_, err := f.Fetch(context.Background(), server.URL+"/private/page")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"/robots.txt": DefaultUserAgent, "/private/page": DefaultUserAgent}, agents)
+
+		// the rules are matched against the User-Agent header of the request
+		browser := &Request{Header: map[string]string{"user-agent": "Mozilla/5.0 (compatible)"}}
+		_, err = f.FetchRequest(context.Background(), server.URL+"/private/other", browser)
+		typ, _ := ErrTypeOf(err)
+		assert.Equal(t, ErrDisallowed, int(typ))
+		_, err = f.FetchRequest(context.Background(), server.URL+"/page", browser)
+		require.NoError(t, err)
+		assert.Equal(t, "Mozilla/5.0 (compatible)", agents["/page"])
+		assert.NotContains(t, agents, "/private/other")
+	}
+	testMap["followedWebsites"] = func(t *testing.T) {
+		testWebsite := Website{
+			URL: "https://example.com",
+			Fetcher: NewPoliteFetcher(mapFetcher{
+				"https://example.com/robots.txt":   testRobotsTxt,
+				"https://example.com":              `<a href="/private/page">Private</a>`,
+				"https://example.com/private/page": `<h1>Private</h1>`,
+			}, Politeness{Robots: true, UserAgent: "SomeBot"}),
+			Elements: []Element{
+				{
+					HtmlElement:        HtmlElement{Typ: "a"},
+					Settings:           Settings{Attribute: "href"},
+					ContentIsFollowURL: &Website{Elements: []Element{{HtmlElement: HtmlElement{Typ: "h1"}}}},
+				},
+			},
+		}
+
+		_, err := testWebsite.Scrape(nil)
+		require.Error(t, err)
+		typ, _ := ErrTypeOf(err)
+		assert.Equal(t, ErrDisallowed, int(typ))
+	}
+
+	for name, test := range testMap {
+		t.Run(name, test)
+	}
+}
@@ -1,7 +1,12 @@
 package scraper
 
 import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/url"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"golang.org/x/net/html"
@@ -9,53 +14,222 @@ import (
 
 // ReplaceObj defines the data structure for an object, that has to be replaced
 type ReplaceObj struct {
-	ToBeReplaced string `json:"toBeReplaced"`
-	Replacement  string `json:"replacement"`
+	ToBeReplaced string `json:"toBeReplaced" yaml:"toBeReplaced"`
+	Replacement  string `json:"replacement" yaml:"replacement"`
 }
 
 // FormatSettings defines the data structure for optional formatting settings of a LookUpElement
 type FormatSettings struct {
-	Replacements []ReplaceObj `json:"replacements"`
-	Trim         []string     `json:"trim"`
-	AddBefore    string       `json:"addBefore"`
-	AddAfter     string       `json:"addAfter"`
+	Replacements []ReplaceObj `json:"replacements" yaml:"replacements"`
+	Trim         []string     `json:"trim" yaml:"trim"`
+	AddBefore    string       `json:"addBefore" yaml:"addBefore"`
+	AddAfter     string       `json:"addAfter" yaml:"addAfter"`
 }
 
 // Settings defines the data structure for optional settings of a LookUpElement
 type Settings struct {
-	FormatSettings           FormatSettings `json:"formatting"`
-	DisallowRecursiveContent bool           `json:"disallowRecursiveContent"`
+	FormatSettings           FormatSettings `json:"formatting" yaml:"formatting"`
+	DisallowRecursiveContent bool           `json:"disallowRecursiveContent" yaml:"disallowRecursiveContent"`
+	// Attribute is the name of an attribute, whose value will be scraped instead of the text of the element
+	Attribute string `json:"attribute" yaml:"attribute"`
+	// ResolveURL resolves the content as a URL against the URL of the website (or its <base> element)
+	ResolveURL bool `json:"resolveURL" yaml:"resolveURL"`
 }
 
 // Tag defines the data structure for an HTML Tag
 type Tag struct {
-	Typ   string `json:"typ"`
-	Value string `json:"value"`
+	Typ   string `json:"typ" yaml:"typ"`
+	Value string `json:"value" yaml:"value"`
+	// Match defines how the value of the attribute is compared to Value, defaults to MatchEquals
+	Match MatchMode `json:"match" yaml:"match"`
+	// Not inverts the match, e.g. an element matches if it does not have the attribute with MatchPresent
+	Not bool `json:"not" yaml:"not"`
 }
 
+// MatchMode defines how the value of an attribute is compared to the Value of a Tag
+type MatchMode string
+
+const (
+	// MatchEquals matches if the value equals Value
+	MatchEquals MatchMode = "equals"
+	// MatchToken matches if the whitespace separated list of the value (e.g. the classes) contains Value
+	MatchToken MatchMode = "token"
+	// MatchPrefix matches if the value starts with Value
+	MatchPrefix MatchMode = "prefix"
+	// MatchSuffix matches if the value ends with Value
+	MatchSuffix MatchMode = "suffix"
+	// MatchSubstring matches if the value contains Value
+	MatchSubstring MatchMode = "substring"
+	// MatchRegex matches if the value matches the regular expression Value
+	MatchRegex MatchMode = "regex"
+	// MatchPresent matches if the element has the attribute, regardless of its value
+	MatchPresent MatchMode = "present"
+)
+
 // HtmlElement defines the data structure for an HTML element
 type HtmlElement struct {
-	Typ  string `json:"typ"`
-	Tags []Tag  `json:"tags"`
+	Typ  string `json:"typ" yaml:"typ"`
+	Tags []Tag  `json:"tags" yaml:"tags"`
+	// Selector is an optional CSS selector, which will be used instead of Typ and Tags if set
+	Selector string `json:"selector" yaml:"selector"`
+	// XPath is an optional XPath expression, which will be used instead of Typ and Tags if set
+	XPath string `json:"xpath" yaml:"xpath"`
 }
 
 // Element defines the data structure for an element to be looked up by the scraper
 type Element struct {
-	HtmlElement        `json:"htmlElement"`
-	Settings           `json:"settings"`
-	ContentIsFollowURL *Website `json:"followURL"`
-	Index              int      `json:"index"`
+	// Name is an optional name, by which the content of the element may be looked up inside of a Result
+	Name               string `json:"name" yaml:"name"`
+	HtmlElement        `json:"htmlElement" yaml:"htmlElement"`
+	Settings           `json:"settings" yaml:"settings"`
+	ContentIsFollowURL *Website `json:"followURL" yaml:"followURL"`
+	// Index is the index of the node to be scraped out of all nodes matching the element,
+	// negative indexes count from the end
+	Index Index `json:"index" yaml:"index"`
+	// All scrapes every node matching the element instead of only the node at Index
+	All bool `json:"all" yaml:"all"`
+	// Slice scrapes the nodes matching the element inside of a range like "1:", ":-1" or "2:5",
+	// following the semantics of slices in Python
+	Slice string `json:"slice" yaml:"slice"`
+	// Elements are child elements looked up inside of each node matching the element, turning the
+	// content of each node into a record of the contents of the child elements,
+	// the Settings and ContentIsFollowURL of the element itself are not used then
+	Elements []Element `json:"elements" yaml:"elements"`
 }
 
 // Website defines the website data type for the scraper
 type Website struct {
-	URL       string    `json:"URL"`
-	Elements  []Element `json:"Elements"`
-	Separator string    `json:"separator"`
+	URL       string    `json:"url" yaml:"url"`
+	Elements  []Element `json:"elements" yaml:"elements"`
+	Separator string    `json:"separator" yaml:"separator"`
+	// AcceptedStatusCodes lists the status codes of a response to be scraped, defaults to every 2xx status code
+	AcceptedStatusCodes []int `json:"acceptedStatusCodes" yaml:"acceptedStatusCodes"`
+	// Charset overrides the character set of the website (e.g. "shift_jis"), which is detected otherwise
+	Charset string `json:"charset" yaml:"charset"`
+	// Retry retries failed fetches of the website, a followed website without a Retry policy uses the one of its parent
+	Retry *RetryPolicy `json:"retry" yaml:"retry"`
+	// Request defines the method, header fields, query parameters and body of the request of the website,
+	// being a plain GET request if not set
+	Request *Request `json:"request" yaml:"request"`
+	// Fetcher is used for fetching URL, a followed website without a Fetcher uses the Fetcher of its parent,
+	// DefaultFetcher will be used if no Fetcher is set at all
+	Fetcher Fetcher `json:"-" yaml:"-"`
 }
 
-// Scrape scrapes the website w, returning the found elements in a string each separated by Separator
+// Scrape scrapes the website w, returning the found elements in a string each separated by Separator,
+// the contents of an element scraping multiple nodes are separated by Separator as well, or by new lines if it is empty
 func (w Website) Scrape(funcs *map[string]interface{}, vars ...interface{}) (string, error) {
+	return w.ScrapeContext(context.Background(), funcs, vars...)
+}
+
+// ScrapeContext scrapes the website w like Scrape, returning ctx.Err() once ctx is done
+func (w Website) ScrapeContext(ctx context.Context, funcs *map[string]interface{}, vars ...interface{}) (string, error) {
+	res, err := w.ScrapeResultContext(ctx, funcs, vars...)
+	if err != nil {
+		return "", err
+	}
+	if err := res.Err(); err != nil {
+		return "", err
+	}
+	return res.String(), nil
+}
+
+// ScrapeResult scrapes the website w, returning the found elements as a structured Result,
+// an error of an element does not abort the scrape but is stored inside of its ElementResult
+func (w Website) ScrapeResult(funcs *map[string]interface{}, vars ...interface{}) (*Result, error) {
+	return w.ScrapeResultContext(context.Background(), funcs, vars...)
+}
+
+// ScrapeResultContext scrapes the website w like ScrapeResult, returning ctx.Err() once ctx is done
+func (w Website) ScrapeResultContext(ctx context.Context, funcs *map[string]interface{}, vars ...interface{}) (*Result, error) {
+	return w.scrape(ctx, nil, funcs, vars...)
+}
+
+// scrape scrapes the website w, inheriting the settings w does not set itself from the page parent,
+// which is nil unless w is followed
+func (w Website) scrape(ctx context.Context, parent *page, funcs *map[string]interface{}, vars ...interface{}) (*Result, error) {
+	p, node, err := w.load(ctx, parent, funcs, vars...)
+	if err != nil {
+		return nil, err
+	}
+	return w.scrapePage(ctx, p, node)
+}
+
+// ScrapeNode scrapes the node tree like ScrapeResult instead of fetching the website, the URL of w is only
+// used for resolving relative URLs and followed websites are still fetched
+func (w Website) ScrapeNode(nodeTree *html.Node) (*Result, error) {
+	return w.ScrapeNodeContext(context.Background(), nodeTree)
+}
+
+// ScrapeNodeContext scrapes the node tree like ScrapeNode, returning ctx.Err() once ctx is done
+func (w Website) ScrapeNodeContext(ctx context.Context, nodeTree *html.Node) (*Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	p := w.newPage(nil)
+	if err := p.setURL(w.URL, nodeTree); err != nil {
+		return nil, err
+	}
+	return w.scrapePage(ctx, p, nodeTree)
+}
+
+// ScrapeReader scrapes the HTML data read from r like ScrapeNode, e.g. an archived page or the body of an email,
+// the data is transcoded to UTF-8 like a fetched website
+func (w Website) ScrapeReader(r io.Reader) (*Result, error) {
+	return w.ScrapeReaderContext(context.Background(), r)
+}
+
+// ScrapeReaderContext scrapes the HTML data read from r like ScrapeReader, returning ctx.Err() once ctx is done
+func (w Website) ScrapeReaderContext(ctx context.Context, r io.Reader) (*Result, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	body, err := DecodeHTML(data, "", w.Charset)
+	if err != nil {
+		return nil, err
+	}
+	return w.scrapeHTML(ctx, string(body))
+}
+
+// ScrapeHTML scrapes the HTML data like ScrapeNode, data is expected to be UTF-8 unless the Charset of w is set
+func (w Website) ScrapeHTML(data string) (*Result, error) {
+	return w.ScrapeHTMLContext(context.Background(), data)
+}
+
+// ScrapeHTMLContext scrapes the HTML data like ScrapeHTML, returning ctx.Err() once ctx is done
+func (w Website) ScrapeHTMLContext(ctx context.Context, data string) (*Result, error) {
+	if w.Charset != "" {
+		return w.ScrapeReaderContext(ctx, strings.NewReader(data))
+	}
+	return w.scrapeHTML(ctx, data)
+}
+
+// scrapeHTML parses the UTF-8 HTML data and scrapes it like ScrapeNode
+func (w Website) scrapeHTML(ctx context.Context, data string) (*Result, error) {
+	nodeTree, err := GetHTMLNode(data)
+	if err != nil {
+		return nil, err
+	}
+	return w.ScrapeNodeContext(ctx, nodeTree)
+}
+
+// scrapePage scrapes the elements of w inside of the node tree of the page p
+func (w Website) scrapePage(ctx context.Context, p *page, node *html.Node) (*Result, error) {
+	res := &Result{URL: p.url, Attempts: p.attempts, separator: w.Separator}
+	for _, el := range w.Elements {
+		elRes := el.scrapeTree(ctx, p, node)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		res.Elements = append(res.Elements, elRes)
+	}
+
+	return res, nil
+}
+
+// load formats the website w using funcs, fetches it and returns the page and its node tree
+func (w Website) load(ctx context.Context, parent *page, funcs *map[string]interface{}, vars ...interface{}) (*page, *html.Node, error) {
 	if funcs != nil {
 		vls := reflect.ValueOf(&w).Elem()
 		for i := 0; i < vls.NumField(); i++ {
@@ -63,52 +237,213 @@ func (w Website) Scrape(funcs *map[string]interface{}, vars ...interface{}) (str
 				vls.Field(i).Set(reflect.ValueOf(formatString(vls.Field(i).String(), *funcs, vars...)))
 			}
 		}
+		w.Request = w.Request.format(*funcs, vars...)
 	}
 
-	htmlData, err := GetHTML(w.URL)
+	p := w.newPage(parent)
+	URL, err := p.request.addQuery(w.URL)
 	if err != nil {
-		return "", err
+		return nil, nil, err
+	}
+	resp, attempts, err := fetchRetry(ctx, p.fetcher, URL, p.request, w.AcceptedStatusCodes, p.retry)
+	if err != nil {
+		return nil, nil, err
 	}
+	p.attempts = attempts
 
-	node, err := GetHTMLNode(htmlData)
+	body, err := DecodeHTML(resp.Body, resp.Header.Get("Content-Type"), w.Charset)
 	if err != nil {
-		return "", err
+		return nil, nil, err
+	}
+	node, err := GetHTMLNode(string(body))
+	if err != nil {
+		return nil, nil, err
 	}
 
-	var elements []string
-	for _, el := range w.Elements {
-		if content, err := el.ScrapeTreeForElement(node); err != nil {
-			return "", err
-		} else {
-			elements = append(elements, content)
-		}
+	if err := p.setURL(resp.URL, node); err != nil {
+		return nil, nil, err
 	}
+	return p, node, nil
+}
 
-	var elementString string
-	for k, v := range elements {
-		elementString += v
-		if k != len(elements)-1 {
-			elementString += w.Separator
+// newPage returns the page of w, inheriting the settings w does not set itself from parent, which may be nil
+func (w Website) newPage(parent *page) *page {
+	p := &page{separator: w.Separator, retry: w.Retry, request: w.Request}
+	if p.separator == "" {
+		p.separator = itemSeparator
+	}
+	var inherited Fetcher
+	if parent != nil {
+		inherited = parent.fetcher
+		if p.retry == nil {
+			p.retry = parent.retry
 		}
+		p.request = w.Request.inherit(parent.request, parent.url, w.URL)
 	}
+	p.fetcher = w.fetcherOf(inherited)
+	return p
+}
+
+// page defines the data structure for a fetched website, whose node tree is being scraped
+type page struct {
+	url string
+	// base is the URL relative URLs are resolved against
+	base *url.URL
+	// fetcher and retry, as well as the header fields of request, are passed on to followed websites
+	fetcher Fetcher
+	retry   *RetryPolicy
+	request *Request
+	// attempts is the number of attempts needed for fetching the page
+	attempts int
+	// separator separates the contents of an element matching multiple nodes
+	separator string
+}
 
-	return elementString, nil
+// itemSeparator separates the contents of an element matching multiple nodes unless the Separator of a website is set
+const itemSeparator = "\n"
+
+// setURL sets the URL of p to the URL pageURL of the node tree, resolving its base URL
+func (p *page) setURL(pageURL string, nodeTree *html.Node) (err error) {
+	p.url = pageURL
+	p.base, err = BaseURL(nodeTree, pageURL)
+	return
 }
 
-// ScrapeTreeForElement scraped the node tree for a lookUpElement.Element and formats the content of it accordingly
+// ScrapeTreeForElement scraped the node tree for a lookUpElement.Element and formats the content of it accordingly,
+// the contents of an element scraping multiple nodes are separated by new lines
 func (e *Element) ScrapeTreeForElement(nodeTree *html.Node) (content string, err error) {
+	return e.ScrapeTreeForElementContext(context.Background(), nodeTree)
+}
+
+// ScrapeTreeForElementContext scrapes the node tree for e like ScrapeTreeForElement, returning ctx.Err() once ctx is done
+func (e *Element) ScrapeTreeForElementContext(ctx context.Context, nodeTree *html.Node) (content string, err error) {
+	base, err := BaseURL(nodeTree, "")
+	if err != nil {
+		return "", err
+	}
+
+	res := e.scrapeTree(ctx, &page{base: base, separator: itemSeparator}, nodeTree)
+	if err := res.err(); err != nil {
+		return "", err
+	}
+	return res.Value, nil
+}
+
+// scrapeTree scrapes the node tree of the page p for e
+func (e *Element) scrapeTree(ctx context.Context, p *page, nodeTree *html.Node) (res ElementResult) {
+	res.Name = e.Name
+	res.URL = p.url
+	if res.Err = ctx.Err(); res.Err != nil {
+		return
+	}
+
 	nodes, err := e.HtmlElement.GetElementNodes(nodeTree)
+	res.Matches = len(nodes)
+	if err != nil {
+		res.Err = err
+		return
+	}
+
+	if e.All || e.Slice != "" {
+		start, end, err := parseSlice(e.Slice, len(nodes))
+		if err != nil {
+			res.Err = err
+			return
+		}
+
+		res.Items = []ElementResult{}
+		var values []string
+		for _, node := range nodes[start:end] {
+			item := e.scrapeNode(ctx, p, node)
+			res.Items = append(res.Items, item)
+			values = append(values, item.Value)
+		}
+		res.Value = strings.Join(values, p.separator)
+		return
+	}
+
+	idx, ok := e.Index.resolve(len(nodes))
+	if !ok {
+		res.Err = newErr(ErrIdxOutOfRange, "element index "+strconv.Itoa(int(e.Index))+" out of range for "+strconv.Itoa(len(nodes))+" matching nodes")
+		return
+	}
+
+	item := e.scrapeNode(ctx, p, nodes[idx])
+	res.Value, res.Followed, res.Elements, res.Err = item.Value, item.Followed, item.Elements, item.Err
+	return
+}
+
+// scrapeNode scrapes the content of a single node matching e, following it if ContentIsFollowURL is set,
+// or scrapes the child elements of e inside of node if e has any
+func (e *Element) scrapeNode(ctx context.Context, p *page, node *html.Node) (res ElementResult) {
+	res.URL = p.url
+
+	if len(e.Elements) > 0 {
+		var values []string
+		for _, child := range e.Elements {
+			childRes := child.scrapeTree(ctx, p, node)
+			res.Elements = append(res.Elements, childRes)
+			values = append(values, childRes.Value)
+		}
+		res.Value = strings.Join(values, p.separator)
+		return
+	}
+
+	content, err := e.contentOf(node)
 	if err != nil {
+		res.Err = err
 		return
 	}
+	content = e.format(content)
 
-	// no node found or index out of range
-	if len := len(nodes) - 1; len < e.Index {
-		return "", newErr(ErrIdxOutOfRange, "element index out of range")
+	if e.Settings.ResolveURL || e.ContentIsFollowURL != nil {
+		if content, res.Err = resolveURL(p.base, content); res.Err != nil {
+			return
+		}
 	}
 
-	content = GetTextOfNode(nodes[e.Index], e.Settings.DisallowRecursiveContent)
+	if e.ContentIsFollowURL != nil {
+		// the URL of the page is checked instead of its base URL, which the page may set itself
+		if strings.HasPrefix(strings.ToLower(content), "file:") && !strings.HasPrefix(strings.ToLower(p.url), "file:") {
+			res.Err = newErr(ErrDisallowed, "following the local file "+content+" from "+p.url+" is not allowed")
+			return
+		}
+		followed := *e.ContentIsFollowURL
+		followed.URL = content
+		res.Followed, res.Err = followed.scrape(ctx, p, nil)
+		if res.Followed != nil {
+			res.Value = res.Followed.String()
+		}
+		return
+	}
 
+	res.Value = content
+	return
+}
+
+// ContentOfNode returns the content of node formatted according to the Settings of e, as if node matched e,
+// without following it or resolving it as a URL
+func (e *Element) ContentOfNode(node *html.Node) (string, error) {
+	content, err := e.contentOf(node)
+	if err != nil {
+		return "", err
+	}
+	return e.format(content), nil
+}
+
+// contentOf returns the value of the Attribute of node if the Attribute setting is set, or the text of node otherwise
+func (e *Element) contentOf(node *html.Node) (string, error) {
+	if e.Settings.Attribute == "" {
+		return GetTextOfNode(node, e.Settings.DisallowRecursiveContent), nil
+	}
+	if val, ok := GetAttributeOfNode(node, e.Settings.Attribute); ok {
+		return val, nil
+	}
+	return "", newErr(ErrMissingAttribute, "missing attribute "+e.Settings.Attribute+" of "+node.Data)
+}
+
+// format formats content according to the FormatSettings of e
+func (e *Element) format(content string) string {
 	for _, r := range e.Settings.FormatSettings.Replacements {
 		content = strings.ReplaceAll(content, r.ToBeReplaced, r.Replacement)
 	}
@@ -127,10 +462,5 @@ func (e *Element) ScrapeTreeForElement(nodeTree *html.Node) (content string, err
 		content = e.Settings.FormatSettings.AddBefore + content
 	}
 
-	if e.ContentIsFollowURL != nil {
-		e.ContentIsFollowURL.URL = content
-		return e.ContentIsFollowURL.Scrape(nil)
-	}
-
-	return content, nil
+	return content
 }
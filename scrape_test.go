@@ -1,7 +1,12 @@
 package scraper
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
@@ -11,12 +16,16 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// testFixtureFetcher replays the responses of the websites used by the tests, set GOSCRAPER_RECORD for re-recording them
+var testFixtureFetcher = NewFixtureFetcher("testdata/fixtures", nil)
+
 func TestScrape(t *testing.T) {
 	testMap := make(map[string]func(t *testing.T), 0)
 
 	testMap["scrapeWebsite_OneElement"] = func(t *testing.T) {
 		testWebsite := Website{
-			URL: "https://www.wikipedia.org/wiki/Wikipedia",
+			URL:     "https://www.wikipedia.org/wiki/Wikipedia",
+			Fetcher: testFixtureFetcher,
 			Elements: []Element{
 				{
 					HtmlElement: HtmlElement{
@@ -41,6 +50,7 @@ func TestScrape(t *testing.T) {
 		testWebsite := Website{
 			Separator: ", ",
 			URL:       "https://www.wikipedia.org/wiki/Wikipedia",
+			Fetcher:   testFixtureFetcher,
 			Elements: []Element{
 				{
 					HtmlElement: HtmlElement{
@@ -75,7 +85,8 @@ func TestScrape(t *testing.T) {
 	testMap["scrapeWebsite_ReplacementFuncs"] = func(t *testing.T) {
 		strToBeReplaced := "{{INSERT_WIKIPEDIA}}"
 		testWebsite := Website{
-			URL: "https://en.wikipedia.org/wiki/" + strToBeReplaced,
+			URL:     "https://en.wikipedia.org/wiki/" + strToBeReplaced,
+			Fetcher: testFixtureFetcher,
 			Elements: []Element{
 				{
 					HtmlElement: HtmlElement{
@@ -104,7 +115,8 @@ func TestScrape(t *testing.T) {
 	testMap["scrapeWebsite_ReplacementFuncsWithVars"] = func(t *testing.T) {
 		numericalDateStr := "{{NUMERICAL_DATE}}" // 2022/05/22
 		testWebsite := Website{
-			URL: "https://www.nytimes.com/issue/todayspaper/{{NUMERICAL_DATE}}/todays-new-york-times",
+			URL:     "https://www.nytimes.com/issue/todayspaper/{{NUMERICAL_DATE}}/todays-new-york-times",
+			Fetcher: testFixtureFetcher,
 			Elements: []Element{
 				{
 					HtmlElement: HtmlElement{
@@ -139,7 +151,7 @@ func TestScrape(t *testing.T) {
 		}
 
 		expected := " The Front Page"
-		content, err := testWebsite.Scrape(&funcs, time.Now())
+		content, err := testWebsite.Scrape(&funcs, time.Date(2022, 5, 22, 0, 0, 0, 0, time.UTC))
 		require.NoError(t, err)
 		assert.Equal(t, expected, content)
 	}
@@ -200,7 +212,49 @@ func TestScrapeTreeForElement(t *testing.T) {
 		}
 		_, err := testElement.ScrapeTreeForElement(nodeTree)
 		require.Error(t, err)
-		assert.Equal(t, "element index out of range", err.Error())
+		assert.Equal(t, "element index 2 out of range for 2 matching nodes", err.Error())
+	}
+	testMap["negativeIndex"] = func(t *testing.T) {
+		testCases := map[Index]string{
+			First: "This is the element with a duplicate",
+			Last:  "This is the second element of the duplicate",
+			-2:    "This is the element with a duplicate",
+		}
+		for idx, expected := range testCases {
+			testElement := Element{
+				HtmlElement: HtmlElement{
+					Typ: "div",
+					Tags: []Tag{
+						{
+							Typ:   "class",
+							Value: "hasDuplicate",
+						},
+					},
+				},
+				Index: idx,
+			}
+			actual, err := testElement.ScrapeTreeForElement(nodeTree)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		}
+	}
+	testMap["negativeIndexOutOfRange"] = func(t *testing.T) {
+		testElement := Element{
+			HtmlElement: HtmlElement{
+				Typ: "div",
+				Tags: []Tag{
+					{
+						Typ:   "class",
+						Value: "hasDuplicate",
+					},
+				},
+			},
+			Index: -3,
+		}
+		_, err := testElement.ScrapeTreeForElement(nodeTree)
+		require.Error(t, err)
+		assert.Equal(t, ErrIdxOutOfRange, int(err.(Error).ErrType))
+		assert.Equal(t, "element index -3 out of range for 2 matching nodes", err.Error())
 	}
 	testMap["settingsReplacements"] = func(t *testing.T) {
 		testElement := Element{
@@ -297,6 +351,46 @@ func TestScrapeTreeForElement(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, expected, actual)
 	}
+	testMap["settingsAttribute"] = func(t *testing.T) {
+		testElement := Element{
+			HtmlElement: HtmlElement{
+				Typ: "a",
+				Tags: []Tag{
+					{
+						Typ:   "id",
+						Value: "websiteLink",
+					},
+				},
+			},
+			Settings: Settings{
+				Attribute: "href",
+			},
+		}
+		expected := "https://wikipedia.com/wiki/Wikipedia"
+		actual, err := testElement.ScrapeTreeForElement(nodeTree)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+	testMap["settingsMissingAttribute"] = func(t *testing.T) {
+		testElement := Element{
+			HtmlElement: HtmlElement{
+				Typ: "a",
+				Tags: []Tag{
+					{
+						Typ:   "id",
+						Value: "websiteLink",
+					},
+				},
+			},
+			Settings: Settings{
+				Attribute: "title",
+			},
+		}
+		_, err := testElement.ScrapeTreeForElement(nodeTree)
+		require.Error(t, err)
+		assert.Equal(t, ErrMissingAttribute, int(err.(Error).ErrType))
+		assert.Equal(t, "missing attribute title of a", err.Error())
+	}
 	testMap["ContentIsFollowURL"] = func(t *testing.T) {
 		testElement := Element{
 			HtmlElement: HtmlElement{
@@ -309,6 +403,7 @@ func TestScrapeTreeForElement(t *testing.T) {
 				},
 			},
 			ContentIsFollowURL: &Website{
+				Fetcher: testFixtureFetcher,
 				Elements: []Element{
 					{
 						HtmlElement: HtmlElement{
@@ -335,3 +430,156 @@ func TestScrapeTreeForElement(t *testing.T) {
 		fmt.Println(testName)
 	}
 }
+
+func TestScrapeContext(t *testing.T) {
+	testMap := make(map[string]func(t *testing.T), 0)
+
+	testMap["canceledBeforeFetch"] = func(t *testing.T) {
+		var fetched bool
+		testWebsite := Website{
+			URL: "https://example.com",
+			Fetcher: FetcherFunc(func(ctx context.Context, URL string) (*Response, error) {
+				fetched = true
+				return nil, errors.New("should not be fetched")
+			}),
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := testWebsite.ScrapeContext(ctx, nil)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.False(t, fetched)
+	}
+	testMap["deadlineWhileFetching"] = func(t *testing.T) {
+		testWebsite := Website{
+			URL: "https://example.com",
+			Fetcher: FetcherFunc(func(ctx context.Context, URL string) (*Response, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}),
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := testWebsite.ScrapeContext(ctx, nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+	testMap["canceledBeforeFollowURL"] = func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		testWebsite := Website{
+			URL: "https://example.com",
+			Fetcher: FetcherFunc(func(ctx context.Context, URL string) (*Response, error) {
+				if URL != "https://example.com" {
+					return nil, errors.New("followed URL should not be fetched")
+				}
+				cancel()
+				return &Response{URL: URL, StatusCode: http.StatusOK, Body: []byte(testHTML)}, nil
+			}),
+			Elements: []Element{
+				{
+					HtmlElement: HtmlElement{
+						Typ: "a",
+						Tags: []Tag{
+							{
+								Typ:   "id",
+								Value: "websiteLink",
+							},
+						},
+					},
+					ContentIsFollowURL: &Website{},
+				},
+			},
+		}
+
+		_, err := testWebsite.ScrapeContext(ctx, nil)
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+	testMap["canceledTreeForElement"] = func(t *testing.T) {
+		nodeTree, err := GetHTMLNode(testHTML)
+		require.NoError(t, err)
+		testElement := Element{
+			HtmlElement: HtmlElement{
+				Typ: "p",
+			},
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = testElement.ScrapeTreeForElementContext(ctx, nodeTree)
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+
+	for testName, testFunc := range testMap {
+		t.Run(testName, testFunc)
+	}
+}
+
+func TestScrapeNode(t *testing.T) {
+	testMap := make(map[string]func(t *testing.T), 0)
+
+	const page = `<base href="https://example.com/wiki/"><h1>Archived</h1><a href="Target">Target</a>`
+	testWebsite := Website{
+		URL:       "https://archive.example.com/page",
+		Separator: ", ",
+		Fetcher:   mapFetcher{"https://example.com/wiki/Target": `<h1>Target</h1>`},
+		Elements: []Element{
+			{HtmlElement: HtmlElement{Typ: "h1"}},
+			{
+				HtmlElement:        HtmlElement{Typ: "a"},
+				Settings:           Settings{Attribute: "href"},
+				ContentIsFollowURL: &Website{Elements: []Element{{HtmlElement: HtmlElement{Typ: "h1"}}}},
+			},
+		},
+	}
+
+	testMap["html"] = func(t *testing.T) {
+		res, err := testWebsite.ScrapeHTML(page)
+		require.NoError(t, err)
+		require.NoError(t, res.Err())
+		assert.Equal(t, "https://archive.example.com/page", res.URL)
+		assert.Equal(t, "Archived, Target", res.String())
+	}
+	testMap["reader"] = func(t *testing.T) {
+		res, err := testWebsite.ScrapeReader(strings.NewReader(page))
+		require.NoError(t, err)
+		assert.Equal(t, "Archived, Target", res.String())
+	}
+	testMap["node"] = func(t *testing.T) {
+		nodeTree, err := GetHTMLNode(page)
+		require.NoError(t, err)
+		res, err := testWebsite.ScrapeNode(nodeTree)
+		require.NoError(t, err)
+		assert.Equal(t, "Archived, Target", res.String())
+	}
+	testMap["fileURL"] = func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte(`<a href="next.html">Next</a>`), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "next.html"), []byte(`<h1>Next page</h1>`), 0o644))
+
+		fileWebsite := Website{
+			URL:     "file://" + filepath.ToSlash(filepath.Join(dir, "index.html")),
+			Fetcher: FileFetcher{},
+			Elements: []Element{
+				{
+					HtmlElement:        HtmlElement{Typ: "a"},
+					Settings:           Settings{Attribute: "href"},
+					ContentIsFollowURL: &Website{Elements: []Element{{HtmlElement: HtmlElement{Typ: "h1"}}}},
+				},
+			},
+		}
+		content, err := fileWebsite.Scrape(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Next page", content)
+	}
+	testMap["canceled"] = func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := testWebsite.ScrapeHTMLContext(ctx, page)
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+
+	for testName, testFunc := range testMap {
+		t.Run(testName, testFunc)
+	}
+}
@@ -0,0 +1,507 @@
+package scraper
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Selector defines the data structure for a compiled CSS selector
+//
+// The supported subset consists of type (including *), class, id and attribute selectors
+// with the operators = ~= |= ^= $= *=, the combinators for descendants, children (>),
+// adjacent (+) and general siblings (~), selector lists (,) and the pseudo-classes
+// :first-child, :last-child, :only-child, :first-of-type, :last-of-type, :only-of-type,
+// :nth-child(), :nth-last-child(), :nth-of-type(), :nth-last-of-type(), :not() and :empty
+type Selector struct {
+	str    string
+	groups []complexSelector
+}
+
+// complexSelector defines compound selectors joined by combinators,
+// combinators[i] joins compounds[i] and compounds[i+1]
+type complexSelector struct {
+	compounds   []compoundSelector
+	combinators []byte
+}
+
+// compoundSelector defines an optional type selector and a list of filters, which all have to match a node
+type compoundSelector struct {
+	typ     string
+	filters []func(*html.Node) bool
+}
+
+// CompileSelector parses sel into a Selector, returning an error of type ErrInvalidSelector if sel is not valid
+func CompileSelector(sel string) (*Selector, error) {
+	p := &selectorParser{str: sel}
+	groups, err := p.parseList()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.str) {
+		return nil, p.err("unexpected " + strconv.QuoteRune(rune(p.str[p.pos])))
+	}
+	return &Selector{str: sel, groups: groups}, nil
+}
+
+// String returns the source of the selector
+func (s *Selector) String() string {
+	return s.str
+}
+
+// Match returns whether node is matched by the selector
+func (s *Selector) Match(node *html.Node) bool {
+	for _, g := range s.groups {
+		if g.match(node, len(g.compounds)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// Select returns all element nodes inside of root (excluding root itself, like querySelectorAll) matched by the selector,
+// in document order
+func (s *Selector) Select(root *html.Node) (nodes []*html.Node) {
+	var crawler func(*html.Node)
+	crawler = func(node *html.Node) {
+		if node.Type == html.ElementNode && s.Match(node) {
+			nodes = append(nodes, node)
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			crawler(child)
+		}
+	}
+	for child := root.FirstChild; child != nil; child = child.NextSibling {
+		crawler(child)
+	}
+	return
+}
+
+// match returns whether node is matched by the compound selector at idx and all compound selectors before it
+func (c complexSelector) match(node *html.Node, idx int) bool {
+	if !c.compounds[idx].match(node) {
+		return false
+	}
+	if idx == 0 {
+		return true
+	}
+
+	switch c.combinators[idx-1] {
+	case ' ':
+		for p := node.Parent; p != nil; p = p.Parent {
+			if c.match(p, idx-1) {
+				return true
+			}
+		}
+	case '>':
+		if p := node.Parent; p != nil {
+			return c.match(p, idx-1)
+		}
+	case '+':
+		if s := prevElementSibling(node); s != nil {
+			return c.match(s, idx-1)
+		}
+	case '~':
+		for s := prevElementSibling(node); s != nil; s = prevElementSibling(s) {
+			if c.match(s, idx-1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// match returns whether node matches the type and all filters of c
+func (c compoundSelector) match(node *html.Node) bool {
+	if node.Type != html.ElementNode {
+		return false
+	}
+	if c.typ != "" && c.typ != "*" && c.typ != node.Data {
+		return false
+	}
+	for _, f := range c.filters {
+		if !f(node) {
+			return false
+		}
+	}
+	return true
+}
+
+// prevElementSibling returns the previous sibling of node being an element node
+func prevElementSibling(node *html.Node) *html.Node {
+	for s := node.PrevSibling; s != nil; s = s.PrevSibling {
+		if s.Type == html.ElementNode {
+			return s
+		}
+	}
+	return nil
+}
+
+// nextElementSibling returns the next sibling of node being an element node
+func nextElementSibling(node *html.Node) *html.Node {
+	for s := node.NextSibling; s != nil; s = s.NextSibling {
+		if s.Type == html.ElementNode {
+			return s
+		}
+	}
+	return nil
+}
+
+// siblingPosition returns the 1-based position of node among its element siblings, counted from
+// the end if fromEnd is set, only counting siblings of the same type if ofType is set
+func siblingPosition(node *html.Node, ofType, fromEnd bool) int {
+	next := prevElementSibling
+	if fromEnd {
+		next = nextElementSibling
+	}
+	pos := 1
+	for s := next(node); s != nil; s = next(s) {
+		if !ofType || s.Data == node.Data {
+			pos++
+		}
+	}
+	return pos
+}
+
+// selectorParser defines the state of parsing a selector string
+type selectorParser struct {
+	str string
+	pos int
+}
+
+// err returns an error of type ErrInvalidSelector describing the problem msg at the current position
+func (p *selectorParser) err(msg string) error {
+	return newErr(ErrInvalidSelector, "invalid selector "+strconv.Quote(p.str)+": "+msg+" at position "+strconv.Itoa(p.pos))
+}
+
+// skipSpace skips all whitespace, returning whether whitespace has been skipped
+func (p *selectorParser) skipSpace() bool {
+	start := p.pos
+	for p.pos < len(p.str) && strings.IndexByte(" \t\n\r\f", p.str[p.pos]) >= 0 {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+// peek returns the current byte or 0 at the end of the selector
+func (p *selectorParser) peek() byte {
+	if p.pos < len(p.str) {
+		return p.str[p.pos]
+	}
+	return 0
+}
+
+// parseList parses a comma separated list of complex selectors
+func (p *selectorParser) parseList() ([]complexSelector, error) {
+	var groups []complexSelector
+	for {
+		p.skipSpace()
+		c, err := p.parseComplex()
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, c)
+
+		p.skipSpace()
+		if p.peek() != ',' {
+			return groups, nil
+		}
+		p.pos++
+	}
+}
+
+// parseComplex parses compound selectors joined by combinators
+func (p *selectorParser) parseComplex() (c complexSelector, err error) {
+	compound, err := p.parseCompound()
+	if err != nil {
+		return
+	}
+	c.compounds = append(c.compounds, compound)
+
+	for {
+		space := p.skipSpace()
+		comb := p.peek()
+		switch comb {
+		case '>', '+', '~':
+			p.pos++
+			p.skipSpace()
+		case ',', ')', 0:
+			return
+		default:
+			if !space {
+				return c, p.err("unexpected " + strconv.QuoteRune(rune(comb)))
+			}
+			comb = ' '
+		}
+
+		if compound, err = p.parseCompound(); err != nil {
+			return
+		}
+		c.compounds = append(c.compounds, compound)
+		c.combinators = append(c.combinators, comb)
+	}
+}
+
+// parseCompound parses a type selector followed by any number of id, class, attribute and pseudo-class selectors
+func (p *selectorParser) parseCompound() (c compoundSelector, err error) {
+	if p.peek() == '*' {
+		p.pos++
+		c.typ = "*"
+	} else if isIdentStart(p.peek()) {
+		c.typ = strings.ToLower(p.parseIdent())
+	}
+
+	for {
+		var filter func(*html.Node) bool
+		switch p.peek() {
+		case '#':
+			p.pos++
+			id := p.parseIdent()
+			if id == "" {
+				return c, p.err("expected id")
+			}
+			filter = func(n *html.Node) bool {
+				val, _ := GetAttributeOfNode(n, "id")
+				return val == id
+			}
+		case '.':
+			p.pos++
+			class := p.parseIdent()
+			if class == "" {
+				return c, p.err("expected class name")
+			}
+			filter = func(n *html.Node) bool {
+				val, _ := GetAttributeOfNode(n, "class")
+				return containsField(val, class)
+			}
+		case '[':
+			p.pos++
+			if filter, err = p.parseAttribute(); err != nil {
+				return
+			}
+		case ':':
+			p.pos++
+			if filter, err = p.parsePseudo(); err != nil {
+				return
+			}
+		default:
+			if c.typ == "" && len(c.filters) == 0 {
+				return c, p.err("expected selector")
+			}
+			return
+		}
+		c.filters = append(c.filters, filter)
+	}
+}
+
+// parseAttribute parses an attribute selector following its opening bracket
+func (p *selectorParser) parseAttribute() (func(*html.Node) bool, error) {
+	p.skipSpace()
+	key := strings.ToLower(p.parseIdent())
+	if key == "" {
+		return nil, p.err("expected attribute name")
+	}
+	p.skipSpace()
+
+	if p.peek() == ']' {
+		p.pos++
+		return func(n *html.Node) bool {
+			_, ok := GetAttributeOfNode(n, key)
+			return ok
+		}, nil
+	}
+
+	var op string
+	if strings.HasPrefix(p.str[p.pos:], "=") {
+		op = "="
+	} else if p.pos+1 < len(p.str) && p.str[p.pos+1] == '=' && strings.IndexByte("~|^$*", p.str[p.pos]) >= 0 {
+		op = p.str[p.pos : p.pos+2]
+	} else {
+		return nil, p.err("expected attribute operator")
+	}
+	p.pos += len(op)
+	p.skipSpace()
+
+	var value string
+	if q := p.peek(); q == '"' || q == '\'' {
+		end := strings.IndexByte(p.str[p.pos+1:], q)
+		if end < 0 {
+			return nil, p.err("unterminated string")
+		}
+		value = p.str[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+	} else if value = p.parseIdent(); value == "" {
+		return nil, p.err("expected attribute value")
+	}
+
+	p.skipSpace()
+	if p.peek() != ']' {
+		return nil, p.err("expected ']'")
+	}
+	p.pos++
+
+	matcher := attributeMatchers[op]
+	return func(n *html.Node) bool {
+		val, ok := GetAttributeOfNode(n, key)
+		return ok && matcher(val, value)
+	}, nil
+}
+
+// attributeMatchers maps the attribute operators to the functions comparing the value of an attribute
+var attributeMatchers = map[string]func(val, value string) bool{
+	"=":  func(val, value string) bool { return val == value },
+	"~=": containsField,
+	"|=": func(val, value string) bool { return val == value || strings.HasPrefix(val, value+"-") },
+	"^=": func(val, value string) bool { return value != "" && strings.HasPrefix(val, value) },
+	"$=": func(val, value string) bool { return value != "" && strings.HasSuffix(val, value) },
+	"*=": func(val, value string) bool { return value != "" && strings.Contains(val, value) },
+}
+
+// containsField returns whether the whitespace separated list val contains value
+func containsField(val, value string) bool {
+	for _, field := range strings.Fields(val) {
+		if field == value {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePseudo parses a pseudo-class following its colon
+func (p *selectorParser) parsePseudo() (func(*html.Node) bool, error) {
+	name := strings.ToLower(p.parseIdent())
+	switch name {
+	case "first-child":
+		return nthFilter(0, 1, false, false), nil
+	case "last-child":
+		return nthFilter(0, 1, false, true), nil
+	case "first-of-type":
+		return nthFilter(0, 1, true, false), nil
+	case "last-of-type":
+		return nthFilter(0, 1, true, true), nil
+	case "only-child":
+		first, last := nthFilter(0, 1, false, false), nthFilter(0, 1, false, true)
+		return func(n *html.Node) bool { return first(n) && last(n) }, nil
+	case "only-of-type":
+		first, last := nthFilter(0, 1, true, false), nthFilter(0, 1, true, true)
+		return func(n *html.Node) bool { return first(n) && last(n) }, nil
+	case "empty":
+		return func(n *html.Node) bool {
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				if c.Type == html.ElementNode || (c.Type == html.TextNode && c.Data != "") {
+					return false
+				}
+			}
+			return true
+		}, nil
+	case "nth-child", "nth-last-child", "nth-of-type", "nth-last-of-type":
+		if p.peek() != '(' {
+			return nil, p.err("expected '('")
+		}
+		p.pos++
+		a, b, err := p.parseNth()
+		if err != nil {
+			return nil, err
+		}
+		return nthFilter(a, b, strings.HasSuffix(name, "of-type"), strings.Contains(name, "last")), nil
+	case "not":
+		if p.peek() != '(' {
+			return nil, p.err("expected '('")
+		}
+		p.pos++
+		groups, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		if p.skipSpace(); p.peek() != ')' {
+			return nil, p.err("expected ')'")
+		}
+		p.pos++
+		inner := &Selector{groups: groups}
+		return func(n *html.Node) bool { return !inner.Match(n) }, nil
+	case "":
+		return nil, p.err("expected pseudo-class")
+	}
+	return nil, p.err("unsupported pseudo-class " + strconv.Quote(name))
+}
+
+// parseNth parses the an+b argument of an nth pseudo-class including its closing parenthesis
+func (p *selectorParser) parseNth() (a, b int, err error) {
+	p.skipSpace()
+	end := strings.IndexByte(p.str[p.pos:], ')')
+	if end < 0 {
+		return 0, 0, p.err("expected ')'")
+	}
+	arg := strings.ToLower(strings.Join(strings.Fields(p.str[p.pos:p.pos+end]), ""))
+
+	switch arg {
+	case "odd":
+		a, b = 2, 1
+	case "even":
+		a, b = 2, 0
+	default:
+		if i := strings.IndexByte(arg, 'n'); i < 0 {
+			b, err = strconv.Atoi(arg)
+		} else {
+			switch coef := arg[:i]; coef {
+			case "", "+":
+				a = 1
+			case "-":
+				a = -1
+			default:
+				a, err = strconv.Atoi(coef)
+			}
+			if offset := arg[i+1:]; err == nil && offset != "" {
+				if offset[0] != '+' && offset[0] != '-' {
+					return 0, 0, p.err("invalid argument " + strconv.Quote(arg))
+				}
+				b, err = strconv.Atoi(offset)
+			}
+		}
+		if err != nil {
+			return 0, 0, p.err("invalid argument " + strconv.Quote(arg))
+		}
+	}
+
+	p.pos += end + 1
+	return
+}
+
+// nthFilter returns a filter matching nodes whose sibling position is a*n+b for any n >= 0
+func nthFilter(a, b int, ofType, fromEnd bool) func(*html.Node) bool {
+	return func(n *html.Node) bool {
+		if n.Parent == nil {
+			return false
+		}
+		pos := siblingPosition(n, ofType, fromEnd)
+		if a == 0 {
+			return pos == b
+		}
+		return (pos-b)%a == 0 && (pos-b)/a >= 0
+	}
+}
+
+// parseIdent parses an identifier, resolving backslash escapes
+func (p *selectorParser) parseIdent() string {
+	var ident strings.Builder
+	for p.pos < len(p.str) {
+		c := p.str[p.pos]
+		if c == '\\' && p.pos+1 < len(p.str) {
+			ident.WriteByte(p.str[p.pos+1])
+			p.pos += 2
+			continue
+		}
+		if !isIdentStart(c) && !(c >= '0' && c <= '9') && c != '-' {
+			break
+		}
+		ident.WriteByte(c)
+		p.pos++
+	}
+	return ident.String()
+}
+
+// isIdentStart returns whether c may start an identifier
+func isIdentStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '-' || c == '\\' || c >= 0x80
+}
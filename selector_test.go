@@ -0,0 +1,162 @@
+package scraper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+)
+
+var testSelectorHTML string = `
+<html>
+<body>
+	<div class="product featured" id="first">
+		<h2 class="title">First product</h2>
+		<span class="price">10</span>
+		<span class="price sale">8</span>
+		<a href="https://example.com/first" lang="en-US">Details</a>
+	</div>
+	<div class="product" id="second">
+		<h2 class="title">Second product</h2>
+		<span class="price">20</span>
+		<p></p>
+		<a href="/second.pdf" data-id="2">Download</a>
+	</div>
+	<section>
+		<p>Outside</p>
+	</section>
+</body>
+</html>
+`
+
+func TestSelector(t *testing.T) {
+	documentNode, err := GetHTMLNode(testSelectorHTML)
+	require.NoError(t, err)
+
+	selectText := func(t *testing.T, selector string) []string {
+		sel, err := CompileSelector(selector)
+		require.NoError(t, err)
+		var texts []string
+		for _, node := range sel.Select(documentNode) {
+			texts = append(texts, GetTextOfNode(node, false))
+		}
+		return texts
+	}
+
+	testCases := map[string][]string{
+		"h2":                                {"First product", "Second product"},
+		"*.title":                           {"First product", "Second product"},
+		"#second h2":                        {"Second product"},
+		"div.product.featured > span.price": {"10", "8"},
+		"div.product > span.price:nth-of-type(2)": {"8"},
+		"body > p":                       nil,
+		"section p, #first a":            {"Details", "Outside"},
+		"h2 + span":                      {"10", "20"},
+		"h2 ~ a":                         {"Details", "Download"},
+		"a[href^='https://']":            {"Details"},
+		"a[href$=\".pdf\"]":              {"Download"},
+		"a[href*=example]":               {"Details"},
+		"span[class~=sale]":              {"8"},
+		"a[lang|=en]":                    {"Details"},
+		"a[data-id]":                     {"Download"},
+		"a[data-id=\"2\"]":               {"Download"},
+		"span:not(.sale)":                {"10", "20"},
+		"div:not(.featured, section) h2": {"Second product"},
+		".product > :first-child":        {"First product", "Second product"},
+		".product > :last-child":         {"Details", "Download"},
+		"span:first-of-type":             {"10", "20"},
+		"span:last-of-type":              {"8", "20"},
+		"span:only-of-type":              {"20"},
+		"section > :only-child":          {"Outside"},
+		".product > :nth-child(odd)":     {"First product", "8", "Second product", ""},
+		".product > :nth-child(2n)":      {"10", "Details", "20", "Download"},
+		".product > :nth-child(-n+2)":    {"First product", "10", "Second product", "20"},
+		".product > :nth-last-child(1)":  {"Details", "Download"},
+		"span:nth-last-of-type(2)":       {"10"},
+		"p:empty":                        {""},
+		"DIV#first > H2":                 {"First product"},
+	}
+
+	for selector, expected := range testCases {
+		selector, expected := selector, expected
+		t.Run(selector, func(t *testing.T) {
+			assert.Equal(t, expected, selectText(t, selector))
+		})
+	}
+}
+
+func TestSelectorMatch(t *testing.T) {
+	documentNode, err := GetHTMLNode(testSelectorHTML)
+	require.NoError(t, err)
+
+	sel, err := CompileSelector("div.product")
+	require.NoError(t, err)
+	nodes := sel.Select(documentNode)
+	require.Equal(t, 2, len(nodes))
+	assert.True(t, sel.Match(nodes[0]))
+	assert.False(t, sel.Match(nodes[0].FirstChild))
+	assert.False(t, sel.Match(&html.Node{Type: html.ElementNode, Data: "span"}))
+	assert.Equal(t, "div.product", sel.String())
+}
+
+func TestCompileSelectorErrors(t *testing.T) {
+	testCases := map[string]string{
+		"":                `invalid selector "": expected selector at position 0`,
+		"div >":           `invalid selector "div >": expected selector at position 5`,
+		"div[":            `invalid selector "div[": expected attribute name at position 4`,
+		"a[href=]":        `invalid selector "a[href=]": expected attribute value at position 7`,
+		"a[href='x]":      `invalid selector "a[href='x]": unterminated string at position 7`,
+		"a[href!=x]":      `invalid selector "a[href!=x]": expected attribute operator at position 6`,
+		"p:hover":         `invalid selector "p:hover": unsupported pseudo-class "hover" at position 7`,
+		"p:nth-child(2x)": `invalid selector "p:nth-child(2x)": invalid argument "2x" at position 12`,
+		"p:not(.a":        `invalid selector "p:not(.a": expected ')' at position 8`,
+		"p)":              `invalid selector "p)": unexpected ')' at position 1`,
+	}
+
+	for selector, expected := range testCases {
+		_, err := CompileSelector(selector)
+		require.Error(t, err, selector)
+		assert.Equal(t, expected, err.Error())
+		assert.Equal(t, ErrInvalidSelector, int(err.(Error).ErrType))
+	}
+}
+
+func TestGetElementNodesSelector(t *testing.T) {
+	documentNode, err := GetHTMLNode(testSelectorHTML)
+	require.NoError(t, err)
+
+	testMap := make(map[string]func(t *testing.T), 0)
+
+	testMap["selector"] = func(t *testing.T) {
+		testElement := Element{
+			HtmlElement: HtmlElement{
+				Typ:      "section",
+				Selector: "div.product > span.price:nth-of-type(2)",
+			},
+		}
+		content, err := testElement.ScrapeTreeForElement(documentNode)
+		require.NoError(t, err)
+		assert.Equal(t, "8", content)
+	}
+	testMap["missingElement"] = func(t *testing.T) {
+		testElement := HtmlElement{
+			Selector: "table td",
+		}
+		_, err := testElement.GetElementNodes(documentNode)
+		require.Error(t, err)
+		assert.Equal(t, "missing table td in the node tree", err.Error())
+	}
+	testMap["invalidSelector"] = func(t *testing.T) {
+		testElement := HtmlElement{
+			Selector: "div >",
+		}
+		_, err := testElement.GetElementNodes(documentNode)
+		require.Error(t, err)
+		assert.Equal(t, ErrInvalidSelector, int(err.(Error).ErrType))
+	}
+
+	for testName, testFunc := range testMap {
+		t.Run(testName, testFunc)
+	}
+}
@@ -0,0 +1,223 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Session is a RequestFetcher keeping the cookies set by its responses inside of a cookie jar and sending them
+// with every further request, so a website and its followed websites (which inherit the Fetcher) share their
+// cookies, as do all scrapes using the same Session
+type Session struct {
+	// Fetcher fetches the requests, which has to be a RequestFetcher for sending cookies, an http.Client using
+	// the cookie jar (and thereby handling the cookies of redirects as well) will be used if Fetcher is nil
+	Fetcher Fetcher
+	// Path is the JSON file the cookies are saved to by Save, may be empty if the cookies are not persisted
+	Path string
+
+	once   sync.Once
+	jar    *sessionJar
+	client *HTTPFetcher
+}
+
+// NewSession returns a Session persisting its cookies to the file at path, loading the cookies saved
+// there by a previous Session if the file exists
+func NewSession(path string) (*Session, error) {
+	s := &Session{Path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var cookies []savedCookie
+	if err := json.Unmarshal(data, &cookies); err != nil {
+		return nil, newErr(ErrInvalidConfig, "invalid session "+path+": "+err.Error())
+	}
+	jar := s.cookieJar()
+	for _, c := range cookies {
+		u, err := url.Parse(c.URL)
+		if err != nil {
+			return nil, newErr(ErrInvalidConfig, "invalid session "+path+": "+err.Error())
+		}
+		jar.SetCookies(u, []*http.Cookie{c.cookie()})
+	}
+	return s, nil
+}
+
+// Jar returns the cookie jar of s, e.g. for adding the cookies of a login
+func (s *Session) Jar() http.CookieJar {
+	return s.cookieJar()
+}
+
+// Fetch fetches URL using the Fetcher of s, sending and storing the cookies of the session
+func (s *Session) Fetch(ctx context.Context, URL string) (*Response, error) {
+	return s.FetchRequest(ctx, URL, nil)
+}
+
+// FetchRequest fetches URL using req like Fetch, adding the cookies of the session to the Cookie header of req
+func (s *Session) FetchRequest(ctx context.Context, URL string, req *Request) (*Response, error) {
+	jar := s.cookieJar()
+	if s.Fetcher == nil {
+		return s.client.FetchRequest(ctx, URL, req)
+	}
+
+	u, err := url.Parse(URL)
+	if err != nil {
+		return nil, err
+	}
+	if cookies := jar.Cookies(u); len(cookies) > 0 {
+		req = req.withCookies(cookies)
+	}
+	resp, err := fetchRequest(ctx, s.Fetcher, URL, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if respURL, err := url.Parse(resp.URL); err == nil && resp.URL != "" {
+		u = respURL
+	}
+	jar.SetCookies(u, (&http.Response{Header: resp.Header}).Cookies())
+	return resp, nil
+}
+
+// Save writes the cookies of s, which have not expired yet, to the file at Path
+func (s *Session) Save() error {
+	if s.Path == "" {
+		return errors.New("the session has no Path to be saved to")
+	}
+	data, err := json.MarshalIndent(s.cookieJar().saved(), "", "  ")
+	if err != nil {
+		return err
+	}
+	// cookies may contain credentials, so only the owner may read the file
+	return os.WriteFile(s.Path, data, 0o600)
+}
+
+// cookieJar returns the cookie jar of s, creating it on first use
+func (s *Session) cookieJar() *sessionJar {
+	s.once.Do(func() {
+		// the public suffix list is built in, so creating the jar cannot fail
+		jar, _ := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+		s.jar = &sessionJar{jar: jar, cookies: make(map[string]savedCookie)}
+		s.client = &HTTPFetcher{Client: &http.Client{Jar: s.jar}}
+	})
+	return s.jar
+}
+
+// withCookies returns a copy of r sending cookies in addition to the ones of its Cookie header
+func (r *Request) withCookies(cookies []*http.Cookie) *Request {
+	req := &Request{}
+	if r != nil {
+		*req = *r
+	}
+
+	pairs := make([]string, 0, len(cookies)+1)
+	req.Header = make(map[string]string, len(req.Header)+1)
+	if r != nil {
+		for key, value := range r.Header {
+			if strings.EqualFold(key, "Cookie") {
+				pairs = append(pairs, value)
+				continue
+			}
+			req.Header[key] = value
+		}
+	}
+	for _, c := range cookies {
+		pairs = append(pairs, c.Name+"="+c.Value)
+	}
+	req.Header["Cookie"] = strings.Join(pairs, "; ")
+	return req
+}
+
+// sessionJar is an http.CookieJar remembering every cookie it stores, which a cookiejar.Jar is not able to list
+type sessionJar struct {
+	jar *cookiejar.Jar
+
+	mu sync.Mutex
+	// cookies contains the latest version of every cookie, keyed by its name, domain and path
+	cookies map[string]savedCookie
+}
+
+// savedCookie defines the data structure for a cookie persisted by Session.Save
+type savedCookie struct {
+	// URL is the URL of the response which set the cookie
+	URL      string    `json:"url"`
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain,omitempty"`
+	Path     string    `json:"path,omitempty"`
+	Expires  time.Time `json:"expires"`
+	Secure   bool      `json:"secure,omitempty"`
+	HttpOnly bool      `json:"httpOnly,omitempty"`
+}
+
+// cookie returns the http.Cookie of c
+func (c savedCookie) cookie() *http.Cookie {
+	return &http.Cookie{Name: c.Name, Value: c.Value, Domain: c.Domain, Path: c.Path, Expires: c.Expires, Secure: c.Secure, HttpOnly: c.HttpOnly}
+}
+
+// SetCookies stores the cookies set by the response of u
+func (j *sessionJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.jar.SetCookies(u, cookies)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	for _, c := range cookies {
+		saved := savedCookie{URL: u.Scheme + "://" + u.Host + u.EscapedPath(), Name: c.Name, Value: c.Value, Domain: c.Domain,
+			Path: c.Path, Expires: c.Expires, Secure: c.Secure, HttpOnly: c.HttpOnly}
+		switch {
+		case c.MaxAge > 0:
+			// a Max-Age is relative to now, which would be reset by loading the cookie again
+			saved.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		case c.MaxAge < 0:
+			saved.Expires = now.Add(-time.Second)
+		}
+
+		domain := c.Domain
+		if domain == "" {
+			domain = u.Hostname()
+		}
+		j.cookies[c.Name+";"+strings.ToLower(strings.TrimPrefix(domain, "."))+";"+c.Path] = saved
+	}
+}
+
+// Cookies returns the cookies to be sent with a request to u
+func (j *sessionJar) Cookies(u *url.URL) []*http.Cookie {
+	return j.jar.Cookies(u)
+}
+
+// saved returns all cookies which have not expired yet, sorted by their key
+func (j *sessionJar) saved() []savedCookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	keys := make([]string, 0, len(j.cookies))
+	for key := range j.cookies {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	now := time.Now()
+	cookies := make([]savedCookie, 0, len(keys))
+	for _, key := range keys {
+		if c := j.cookies[key]; c.Expires.IsZero() || c.Expires.After(now) {
+			cookies = append(cookies, c)
+		}
+	}
+	return cookies
+}
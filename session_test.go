@@ -0,0 +1,112 @@
+package scraper
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession(t *testing.T) {
+	testMap := make(map[string]func(t *testing.T), 0)
+
+	testMap["followedWebsites"] = func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/login":
+				http.SetCookie(w, &http.Cookie{Name: "session", Value: "fruit", Path: "/"})
+				http.Redirect(w, r, "/shop", http.StatusFound)
+			case "/shop":
+				io.WriteString(w, `<a href="/account">Account</a>`)
+			case "/account":
+				c, err := r.Cookie("session")
+				if err != nil {
+					http.Error(w, "login required", http.StatusUnauthorized)
+					return
+				}
+				io.WriteString(w, "<h1>Account of "+c.Value+"</h1>")
+			}
+		}))
+		defer server.Close()
+
+		testWebsite := Website{
+			URL:     server.URL + "/login",
+			Fetcher: &Session{},
+			Elements: []Element{
+				{
+					HtmlElement:        HtmlElement{Typ: "a"},
+					Settings:           Settings{Attribute: "href"},
+					ContentIsFollowURL: &Website{Elements: []Element{{HtmlElement: HtmlElement{Typ: "h1"}}}},
+				},
+			},
+		}
+		content, err := testWebsite.Scrape(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Account of fruit", content)
+
+		// without a session the cookie is lost
+		testWebsite.Fetcher = nil
+		_, err = testWebsite.Scrape(nil)
+		require.Error(t, err)
+		assert.Equal(t, http.StatusUnauthorized, err.(StatusError).StatusCode)
+	}
+	testMap["customFetcher"] = func(t *testing.T) {
+		var cookies []string
+		session := &Session{Fetcher: requestFetcherFunc(func(ctx context.Context, URL string, req *Request) (*Response, error) {
+			cookie := ""
+			if req != nil {
+				cookie = req.Header["Cookie"]
+			}
+			cookies = append(cookies, cookie)
+			header := http.Header{"Set-Cookie": {"visits=" + string(rune('0'+len(cookies))) + "; Path=/"}}
+			return &Response{URL: URL, StatusCode: http.StatusOK, Header: header, Body: []byte("<h1>Page</h1>")}, nil
+		})}
+
+		_, err := session.Fetch(context.Background(), "https://example.com/a")
+		require.NoError(t, err)
+		_, err = session.FetchRequest(context.Background(), "https://example.com/b", &Request{Header: map[string]string{"cookie": "lang=de"}})
+		require.NoError(t, err)
+		_, err = session.Fetch(context.Background(), "https://other.example.org/")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"", "lang=de; visits=1", ""}, cookies)
+	}
+	testMap["saveAndLoad"] = func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "session.json")
+		session, err := NewSession(path)
+		require.NoError(t, err)
+
+		u, _ := url.Parse("https://example.com/shop/cart")
+		session.Jar().SetCookies(u, []*http.Cookie{
+			{Name: "session", Value: "fruit"},
+			{Name: "remember", Value: "1", Path: "/", MaxAge: 3600},
+			{Name: "old", Value: "1", Path: "/", Expires: time.Now().Add(-time.Hour)},
+		})
+		require.NoError(t, session.Save())
+
+		loaded, err := NewSession(path)
+		require.NoError(t, err)
+		var names []string
+		for _, c := range loaded.Jar().Cookies(u) {
+			names = append(names, c.Name+"="+c.Value)
+		}
+		assert.ElementsMatch(t, []string{"session=fruit", "remember=1"}, names)
+
+		// the cookie without a Path applies to the directory of its URL only
+		root, _ := url.Parse("https://example.com/")
+		require.Len(t, loaded.Jar().Cookies(root), 1)
+		assert.Equal(t, "remember", loaded.Jar().Cookies(root)[0].Name)
+
+		assert.Error(t, (&Session{}).Save())
+	}
+
+	for testName, testFunc := range testMap {
+		t.Run(testName, testFunc)
+	}
+}
@@ -0,0 +1,295 @@
+package scraper
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// FieldError defines the data structure for an error which occurred while unmarshalling a single field
+type FieldError struct {
+	// Field is the path of the field, e.g. Products[2].Price
+	Field string
+	Err   error
+}
+
+// Error returns the error msg of a FieldError
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+// UnmarshalError defines the data structure for an error of type ErrUnmarshal, listing the errors of all fields
+type UnmarshalError struct {
+	ErrType
+	Fields []FieldError
+}
+
+// Error returns the error msg of an UnmarshalError
+func (e UnmarshalError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for k, f := range e.Fields {
+		msgs[k] = f.Error()
+	}
+	return "unmarshal: " + strings.Join(msgs, "; ")
+}
+
+// Unmarshal scrapes the node tree into v, which has to be a pointer to a struct
+//
+// Only fields having a scrape tag are scraped. The tag consists of a CSS selector, which is looked up
+// inside of the node tree, optionally followed by the comma separated options attr=<name> (scraping the
+// attribute instead of the text), index=<index> (see Index, defaults to 0) and layout=<layout> (the layout
+// of time.Time fields, defaults to time.RFC3339), e.g. `scrape:"a.next,attr=href"`. An empty selector
+// refers to the node tree itself.
+//
+// Fields of the types string, bool, int, uint and float (in all sizes), time.Time and url.URL are scraped
+// from the trimmed content of the node, nested structs are unmarshalled from the node itself and slices
+// contain an item for every matching node. Pointer fields stay nil if there is no matching node.
+// An UnmarshalError listing every field which could not be unmarshalled will be returned on failure.
+func Unmarshal(nodeTree *html.Node, v interface{}) error {
+	base, err := BaseURL(nodeTree, "")
+	if err != nil {
+		return err
+	}
+	return unmarshal(nodeTree, base, v)
+}
+
+// ScrapeInto scrapes the website w into v like Unmarshal, ignoring the Elements of w
+func (w Website) ScrapeInto(v interface{}, funcs *map[string]interface{}, vars ...interface{}) error {
+	return w.ScrapeIntoContext(context.Background(), v, funcs, vars...)
+}
+
+// ScrapeIntoContext scrapes the website w into v like ScrapeInto, returning ctx.Err() once ctx is done
+func (w Website) ScrapeIntoContext(ctx context.Context, v interface{}, funcs *map[string]interface{}, vars ...interface{}) error {
+	p, node, err := w.load(ctx, nil, funcs, vars...)
+	if err != nil {
+		return err
+	}
+	return unmarshal(node, p.base, v)
+}
+
+// unmarshal scrapes the node tree into v, resolving URLs against base
+func unmarshal(nodeTree *html.Node, base *url.URL, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return newErr(ErrUnmarshal, "unmarshal: v has to be a non-nil pointer to a struct")
+	}
+
+	d := &decoder{base: base}
+	d.decodeStruct(nodeTree, rv.Elem(), "")
+	if len(d.errs) > 0 {
+		return UnmarshalError{ErrType: ErrUnmarshal, Fields: d.errs}
+	}
+	return nil
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	urlType  = reflect.TypeOf(url.URL{})
+)
+
+// scrapeTag defines the data structure for the parsed scrape tag of a field
+type scrapeTag struct {
+	selector *Selector
+	attr     string
+	index    Index
+	layout   string
+}
+
+// parseScrapeTag parses the scrape tag str of a field
+func parseScrapeTag(str string) (tag scrapeTag, err error) {
+	tag.layout = time.RFC3339
+
+	parts := strings.Split(str, ",")
+	// options are trailing, so the selector ends with the last part which is not an attr, index or layout
+	// option, keeping commas and equals signs inside of the selector, e.g. "h1, h2[class=t],attr=id"
+	end := len(parts)
+options:
+	for ; end > 1; end-- {
+		key, val, ok := strings.Cut(strings.TrimSpace(parts[end-1]), "=")
+		if !ok {
+			break
+		}
+		switch key {
+		case "attr":
+			tag.attr = val
+		case "index":
+			if tag.index, err = parseIndex(val); err != nil {
+				return
+			}
+		case "layout":
+			tag.layout = val
+		default:
+			break options
+		}
+	}
+
+	if sel := strings.TrimSpace(strings.Join(parts[:end], ",")); sel != "" {
+		tag.selector, err = CompileSelector(sel)
+	}
+	return
+}
+
+// nodes returns the nodes inside of node matching the selector of t, or node itself if t has no selector
+func (t scrapeTag) nodes(node *html.Node) []*html.Node {
+	if t.selector == nil {
+		return []*html.Node{node}
+	}
+	return t.selector.Select(node)
+}
+
+// content returns the trimmed text of node, or the value of its attribute attr if set
+func (t scrapeTag) content(node *html.Node) (string, error) {
+	if t.attr == "" {
+		return strings.TrimSpace(GetTextOfNode(node, false)), nil
+	}
+	if val, ok := GetAttributeOfNode(node, t.attr); ok {
+		return strings.TrimSpace(val), nil
+	}
+	return "", newErr(ErrMissingAttribute, "missing attribute "+t.attr+" of "+node.Data)
+}
+
+// decoder defines the state of unmarshalling a node tree
+type decoder struct {
+	base *url.URL
+	errs []FieldError
+}
+
+// fail records err for the field at path
+func (d *decoder) fail(path string, err error) {
+	d.errs = append(d.errs, FieldError{Field: path, Err: err})
+}
+
+// decodeStruct unmarshals node into all tagged fields of the struct v
+func (d *decoder) decodeStruct(node *html.Node, v reflect.Value, path string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		str, ok := f.Tag.Lookup("scrape")
+		if !ok || str == "-" || f.PkgPath != "" {
+			continue
+		}
+
+		fieldPath := f.Name
+		if path != "" {
+			fieldPath = path + "." + f.Name
+		}
+
+		tag, err := parseScrapeTag(str)
+		if err != nil {
+			d.fail(fieldPath, err)
+			continue
+		}
+		d.decodeField(node, v.Field(i), tag, fieldPath)
+	}
+}
+
+// decodeField unmarshals the nodes inside of node matching tag into the field v
+func (d *decoder) decodeField(node *html.Node, v reflect.Value, tag scrapeTag, path string) {
+	nodes := tag.nodes(node)
+
+	if v.Kind() == reflect.Slice {
+		items := reflect.MakeSlice(v.Type(), len(nodes), len(nodes))
+		for k, n := range nodes {
+			d.decodeValue(n, items.Index(k), tag, path+"["+strconv.Itoa(k)+"]")
+		}
+		v.Set(items)
+		return
+	}
+
+	idx, ok := tag.index.resolve(len(nodes))
+	if !ok {
+		if v.Kind() != reflect.Ptr {
+			// an empty selector refers to the node tree itself, which only has the index 0
+			missing := "index " + strconv.Itoa(int(tag.index)) + " of the node tree"
+			if tag.selector != nil {
+				missing = tag.selector.String() + " in the node tree"
+			}
+			d.fail(path, newErr(ErrMissingElement, "missing "+missing))
+		}
+		return
+	}
+	d.decodeValue(nodes[idx], v, tag, path)
+}
+
+// decodeValue unmarshals node into v
+func (d *decoder) decodeValue(node *html.Node, v reflect.Value, tag scrapeTag, path string) {
+	if v.Kind() == reflect.Ptr {
+		ptr := reflect.New(v.Type().Elem())
+		d.decodeValue(node, ptr.Elem(), tag, path)
+		v.Set(ptr)
+		return
+	}
+	if v.Kind() == reflect.Struct && v.Type() != timeType && v.Type() != urlType {
+		d.decodeStruct(node, v, path)
+		return
+	}
+
+	content, err := tag.content(node)
+	if err != nil {
+		d.fail(path, err)
+		return
+	}
+	if err := d.set(v, content, tag); err != nil {
+		d.fail(path, err)
+	}
+}
+
+// set converts content into the type of v and stores it inside of v
+func (d *decoder) set(v reflect.Value, content string, tag scrapeTag) error {
+	switch v.Type() {
+	case timeType:
+		t, err := time.Parse(tag.layout, content)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case urlType:
+		u, err := url.Parse(content)
+		if err != nil {
+			return err
+		}
+		if d.base != nil {
+			u = d.base.ResolveReference(u)
+		}
+		v.Set(reflect.ValueOf(*u))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(content)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(content)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(content, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(content, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(content, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return newErr(ErrUnmarshal, "unsupported type "+v.Type().String())
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package scraper
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testUnmarshalHTML = `<html><body>
+<h1> Shop </h1>
+<time>2026-10-18</time>
+<ul>
+	<li class="product"><a href="/p/1">Apple</a><span class="price">1.5</span><span class="stock">3</span></li>
+	<li class="product"><a href="/p/2">Pear</a><span class="price">2.25</span><span class="stock">0</span></li>
+</ul>
+<a class="next" href="?page=2">Next</a>
+</body></html>`
+
+type testProduct struct {
+	Name  string  `scrape:"a"`
+	Link  string  `scrape:"a,attr=href"`
+	Price float64 `scrape:".price"`
+	Stock uint    `scrape:".stock"`
+}
+
+type testShop struct {
+	Title    string        `scrape:"h1"`
+	Updated  time.Time     `scrape:"time,layout=2006-01-02"`
+	Products []testProduct `scrape:"li.product"`
+	Last     testProduct   `scrape:"li.product,index=last"`
+	Next     *url.URL      `scrape:"a.next,attr=href"`
+	Sale     *string       `scrape:".sale"`
+	Ignored  string
+	Skipped  string `scrape:"-"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	testMap := make(map[string]func(t *testing.T), 0)
+
+	testMap["intoStruct"] = func(t *testing.T) {
+		nodeTree, err := GetHTMLNode(testUnmarshalHTML)
+		require.NoError(t, err)
+
+		var shop testShop
+		require.NoError(t, Unmarshal(nodeTree, &shop))
+
+		assert.Equal(t, "Shop", shop.Title)
+		assert.Equal(t, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), shop.Updated)
+		assert.Equal(t, []testProduct{
+			{Name: "Apple", Link: "/p/1", Price: 1.5, Stock: 3},
+			{Name: "Pear", Link: "/p/2", Price: 2.25, Stock: 0},
+		}, shop.Products)
+		assert.Equal(t, "Pear", shop.Last.Name)
+		require.NotNil(t, shop.Next)
+		assert.Equal(t, "?page=2", shop.Next.String())
+		assert.Nil(t, shop.Sale)
+	}
+	testMap["scrapeInto"] = func(t *testing.T) {
+		testWebsite := Website{
+			URL:     "https://example.com/shop",
+			Fetcher: mapFetcher{"https://example.com/shop": testUnmarshalHTML},
+		}
+
+		var shop testShop
+		require.NoError(t, testWebsite.ScrapeInto(&shop, nil))
+		assert.Len(t, shop.Products, 2)
+		assert.Equal(t, "https://example.com/shop?page=2", shop.Next.String())
+	}
+	testMap["fieldErrors"] = func(t *testing.T) {
+		nodeTree, err := GetHTMLNode(testUnmarshalHTML)
+		require.NoError(t, err)
+
+		var v struct {
+			Products []struct {
+				Price int `scrape:".price"`
+			} `scrape:"li.product"`
+			Missing string  `scrape:"table"`
+			Option  string  `scrape:"h1,unknown=1"`
+			Index   string  `scrape:",index=1"`
+			Pointer *string `scrape:",index=1"`
+		}
+		err = Unmarshal(nodeTree, &v)
+
+		var unmarshalErr UnmarshalError
+		require.True(t, errors.As(err, &unmarshalErr))
+		assert.Equal(t, ErrUnmarshal, int(unmarshalErr.ErrType))
+
+		var fields []string
+		for _, f := range unmarshalErr.Fields {
+			fields = append(fields, f.Field)
+		}
+		assert.Equal(t, []string{"Products[0].Price", "Products[1].Price", "Missing", "Option", "Index"}, fields)
+		assert.Nil(t, v.Pointer)
+	}
+	testMap["selectorWithCommas"] = func(t *testing.T) {
+		nodeTree, err := GetHTMLNode(testUnmarshalHTML)
+		require.NoError(t, err)
+
+		var v struct {
+			Title string   `scrape:"h1, h2[class=t]"`
+			Links []string `scrape:"li[class=product] a, a[class=next],attr=href"`
+			Price string   `scrape:"li[class=product] .price, .sale,index=last"`
+		}
+		require.NoError(t, Unmarshal(nodeTree, &v))
+		assert.Equal(t, "Shop", v.Title)
+		assert.Equal(t, []string{"/p/1", "/p/2", "?page=2"}, v.Links)
+		assert.Equal(t, "2.25", v.Price)
+	}
+	testMap["invalidTarget"] = func(t *testing.T) {
+		nodeTree, err := GetHTMLNode(testUnmarshalHTML)
+		require.NoError(t, err)
+
+		var s string
+		assert.Error(t, Unmarshal(nodeTree, &s))
+		assert.Error(t, Unmarshal(nodeTree, testShop{}))
+	}
+
+	for testName, testFunc := range testMap {
+		t.Run(testName, testFunc)
+	}
+}
@@ -0,0 +1,71 @@
+package scraper
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// BaseURL returns the URL relative URLs inside of nodeTree have to be resolved against, being the href
+// of the first <base> element resolved against pageURL, or pageURL itself if there is no <base> element,
+// nil will be returned if neither pageURL nor a <base> element is available
+func BaseURL(nodeTree *html.Node, pageURL string) (*url.URL, error) {
+	var base *url.URL
+	if pageURL != "" {
+		u, err := url.Parse(pageURL)
+		if err != nil {
+			return nil, err
+		}
+		base = u
+	}
+
+	href, ok := baseHref(nodeTree)
+	if !ok {
+		return base, nil
+	}
+	u, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return nil, err
+	}
+	if base == nil {
+		return u, nil
+	}
+	return base.ResolveReference(u), nil
+}
+
+// ResolveURL resolves ref against the BaseURL of nodeTree, which has been fetched from pageURL
+func ResolveURL(nodeTree *html.Node, pageURL, ref string) (string, error) {
+	base, err := BaseURL(nodeTree, pageURL)
+	if err != nil {
+		return "", err
+	}
+	return resolveURL(base, ref)
+}
+
+// resolveURL resolves ref against base, returning ref unchanged if base is nil
+func resolveURL(base *url.URL, ref string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return "", err
+	}
+	if base == nil {
+		return u.String(), nil
+	}
+	return base.ResolveReference(u).String(), nil
+}
+
+// baseHref returns the href of the first <base> element inside of nodeTree having one
+func baseHref(nodeTree *html.Node) (string, bool) {
+	if nodeTree.Type == html.ElementNode && nodeTree.Data == "base" {
+		if href, ok := GetAttributeOfNode(nodeTree, "href"); ok {
+			return href, true
+		}
+	}
+	for child := nodeTree.FirstChild; child != nil; child = child.NextSibling {
+		if href, ok := baseHref(child); ok {
+			return href, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,134 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveURL(t *testing.T) {
+	withBase, err := GetHTMLNode(`<html><head><base href="/docs/"></head><body><a href="page.html">Page</a></body></html>`)
+	require.NoError(t, err)
+	withoutBase, err := GetHTMLNode(`<html><body><a href="page.html">Page</a></body></html>`)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		pageURL string
+		ref     string
+		baseTag bool
+		result  string
+	}{
+		{"relativePath", "https://example.com/wiki/Foo", "Bar", false, "https://example.com/wiki/Bar"},
+		{"absolutePath", "https://example.com/wiki/Foo", "/wiki/Bar", false, "https://example.com/wiki/Bar"},
+		{"absoluteURL", "https://example.com/wiki/Foo", "https://example.org/", false, "https://example.org/"},
+		{"protocolRelative", "https://example.com/wiki/Foo", "//example.org/a", false, "https://example.org/a"},
+		{"surroundingWhitespace", "https://example.com/wiki/Foo", " ../Bar\n", false, "https://example.com/Bar"},
+		{"baseTag", "https://example.com/wiki/Foo", "page.html", true, "https://example.com/docs/page.html"},
+		{"baseTagWithoutPageURL", "", "page.html", true, "/docs/page.html"},
+		{"withoutPageURL", "", "page.html", false, "page.html"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			nodeTree := withoutBase
+			if tc.baseTag {
+				nodeTree = withBase
+			}
+			resolved, err := ResolveURL(nodeTree, tc.pageURL, tc.ref)
+			require.NoError(t, err)
+			assert.Equal(t, tc.result, resolved)
+		})
+	}
+}
+
+func TestFollowRelativeURL(t *testing.T) {
+	testMap := make(map[string]func(t *testing.T), 0)
+
+	linkElement := func(followed *Website) Element {
+		return Element{
+			HtmlElement: HtmlElement{
+				Typ: "a",
+			},
+			Settings: Settings{
+				Attribute: "href",
+			},
+			ContentIsFollowURL: followed,
+		}
+	}
+	headingWebsite := &Website{
+		Elements: []Element{
+			{
+				HtmlElement: HtmlElement{
+					Typ: "h1",
+				},
+			},
+		},
+	}
+
+	testMap["relativeToFinalURL"] = func(t *testing.T) {
+		testWebsite := Website{
+			URL: "https://example.com/redirect",
+			Fetcher: FetcherFunc(func(ctx context.Context, URL string) (*Response, error) {
+				switch URL {
+				case "https://example.com/redirect":
+					return &Response{URL: "https://example.com/wiki/Start", StatusCode: http.StatusOK, Body: []byte(`<a href="Target">Target</a>`)}, nil
+				case "https://example.com/wiki/Target":
+					return &Response{URL: URL, StatusCode: http.StatusOK, Body: []byte(`<h1>Target</h1>`)}, nil
+				}
+				return nil, errors.New("no page for " + URL)
+			}),
+			Elements: []Element{linkElement(headingWebsite)},
+		}
+
+		content, err := testWebsite.Scrape(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Target", content)
+	}
+	testMap["relativeToBaseTag"] = func(t *testing.T) {
+		testWebsite := Website{
+			URL: "https://example.com/wiki/Start",
+			Fetcher: mapFetcher{
+				"https://example.com/wiki/Start":    `<base href="https://example.org/mirror/"><a href="./Target">Target</a>`,
+				"https://example.org/mirror/Target": `<h1>Mirrored target</h1>`,
+			},
+			Elements: []Element{linkElement(headingWebsite)},
+		}
+
+		content, err := testWebsite.Scrape(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Mirrored target", content)
+	}
+	testMap["resolveURLSetting"] = func(t *testing.T) {
+		testWebsite := Website{
+			URL: "https://example.com/wiki/Start",
+			Fetcher: mapFetcher{
+				"https://example.com/wiki/Start": `<a href="/images/logo.png">Logo</a>`,
+			},
+			Elements: []Element{
+				{
+					HtmlElement: HtmlElement{
+						Typ: "a",
+					},
+					Settings: Settings{
+						Attribute:  "href",
+						ResolveURL: true,
+					},
+				},
+			},
+		}
+
+		content, err := testWebsite.Scrape(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/images/logo.png", content)
+	}
+
+	for testName, testFunc := range testMap {
+		t.Run(testName, testFunc)
+	}
+}
@@ -0,0 +1,170 @@
+package scraper
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// ValidationError defines the data structure for an error of type ErrInvalidConfig, listing every problem of a Website
+type ValidationError struct {
+	ErrType
+	// Fields contains the problems, their Field being the path of the invalid part, e.g. Elements[2].followURL.Elements[0]
+	Fields []FieldError
+}
+
+// Error returns the error msg of a ValidationError
+func (e ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for k, f := range e.Fields {
+		msgs[k] = f.Error()
+	}
+	return "invalid website: " + strings.Join(msgs, "; ")
+}
+
+// Validate checks the whole definition of the website w, including followed websites, without fetching anything,
+// returning a ValidationError listing every problem found
+func (w Website) Validate() error {
+	v := &validator{}
+	if w.URL == "" {
+		v.fail("URL", "missing URL")
+	}
+	v.website(&w, "")
+
+	if len(v.errs) > 0 {
+		return ValidationError{ErrType: ErrInvalidConfig, Fields: v.errs}
+	}
+	return nil
+}
+
+// validator defines the state of validating a website
+type validator struct {
+	errs []FieldError
+	// websites is the chain of websites currently being validated, used for detecting follow cycles
+	websites []*Website
+}
+
+// fail records the problem msg of the part at path
+func (v *validator) fail(path, msg string) {
+	v.errs = append(v.errs, FieldError{Field: path, Err: newErr(ErrInvalidConfig, msg)})
+}
+
+// joinPath joins the path of a parent and the name of one of its parts
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// website validates the website w at path
+func (v *validator) website(w *Website, path string) {
+	for _, parent := range v.websites {
+		if parent == w {
+			v.fail(path, "followed websites form a cycle")
+			return
+		}
+	}
+	v.websites = append(v.websites, w)
+	defer func() { v.websites = v.websites[:len(v.websites)-1] }()
+
+	for _, code := range w.AcceptedStatusCodes {
+		if code < 100 || code > 599 {
+			v.fail(joinPath(path, "acceptedStatusCodes"), "invalid status code "+strconv.Itoa(code))
+		}
+	}
+	if r := w.Retry; r != nil {
+		retryPath := joinPath(path, "retry")
+		if r.MaxAttempts < 0 {
+			v.fail(retryPath, "negative maxAttempts")
+		}
+		if r.BaseDelay < 0 || r.MaxDelay < 0 {
+			v.fail(retryPath, "negative delay")
+		} else if r.MaxDelay > 0 && r.BaseDelay > r.MaxDelay {
+			v.fail(retryPath, "baseDelay exceeds maxDelay")
+		}
+		if r.Jitter < 0 || r.Jitter > 1 {
+			v.fail(retryPath, "jitter has to be between 0 and 1")
+		}
+		for _, code := range r.RetryStatusCodes {
+			if code < 100 || code > 599 {
+				v.fail(retryPath, "invalid status code "+strconv.Itoa(code))
+			}
+		}
+	}
+	if w.Charset != "" && !validCharset(w.Charset) {
+		v.fail(joinPath(path, "charset"), "unknown charset "+w.Charset)
+	}
+	if r := w.Request; r != nil {
+		requestPath := joinPath(path, "request")
+		if r.Method != "" && !httpguts.ValidHeaderFieldName(r.Method) {
+			v.fail(joinPath(requestPath, "method"), "invalid method "+strconv.Quote(r.Method))
+		}
+		names := make([]string, 0, len(r.Header))
+		for name := range r.Header {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if value := r.Header[name]; !httpguts.ValidHeaderFieldName(name) {
+				v.fail(joinPath(requestPath, "header"), "invalid header field name "+strconv.Quote(name))
+			} else if !httpguts.ValidHeaderFieldValue(value) {
+				v.fail(joinPath(requestPath, "header"), "invalid value of header field "+name)
+			}
+		}
+	}
+	v.elements(w.Elements, path)
+}
+
+// elements validates the elements els of the parent at path
+func (v *validator) elements(els []Element, path string) {
+	for k := range els {
+		v.element(&els[k], joinPath(path, "Elements["+strconv.Itoa(k)+"]"))
+	}
+}
+
+// element validates the element e at path
+func (v *validator) element(e *Element, path string) {
+	switch {
+	case e.Selector != "":
+		if _, err := CompileSelector(e.Selector); err != nil {
+			v.fail(path, err.Error())
+		}
+	case e.XPath != "":
+		if _, err := CompileXPath(e.XPath); err != nil {
+			v.fail(path, err.Error())
+		}
+	case e.Typ == "":
+		v.fail(path, "missing typ, selector or xpath")
+	default:
+		for k, tag := range e.Tags {
+			if tag.Typ == "" {
+				v.fail(path, "missing typ of tag "+strconv.Itoa(k))
+			} else if _, err := tag.matcher(); err != nil {
+				v.fail(path, err.Error())
+			}
+		}
+	}
+
+	if e.Slice != "" {
+		if _, _, err := parseSlice(e.Slice, 0); err != nil {
+			v.fail(path, err.Error())
+		}
+	}
+	if e.Index != First && (e.All || e.Slice != "") {
+		v.fail(path, "index is not used together with all or slice")
+	}
+
+	if len(e.Elements) > 0 {
+		if e.ContentIsFollowURL != nil {
+			v.fail(path, "followURL is not used by an element with child elements")
+		}
+		v.elements(e.Elements, path)
+		return
+	}
+	if e.ContentIsFollowURL != nil {
+		v.website(e.ContentIsFollowURL, joinPath(path, "followURL"))
+	}
+}
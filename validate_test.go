@@ -0,0 +1,95 @@
+package scraper
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	testMap := make(map[string]func(t *testing.T), 0)
+
+	testMap["valid"] = func(t *testing.T) {
+		testWebsite := Website{
+			URL: "https://example.com",
+			Elements: []Element{
+				{HtmlElement: HtmlElement{Typ: "a", Tags: []Tag{{Typ: "class", Value: "next", Match: MatchToken}}}, ContentIsFollowURL: &Website{
+					Elements: []Element{{HtmlElement: HtmlElement{XPath: "//h1"}}},
+				}},
+				{HtmlElement: HtmlElement{Selector: "li"}, Slice: "1:", Elements: []Element{
+					{HtmlElement: HtmlElement{Typ: "span"}, Index: Last},
+				}},
+			},
+		}
+		assert.NoError(t, testWebsite.Validate())
+	}
+	testMap["problems"] = func(t *testing.T) {
+		cyclic := &Website{}
+		cyclic.Elements = []Element{{HtmlElement: HtmlElement{Typ: "a"}, ContentIsFollowURL: cyclic}}
+
+		testWebsite := Website{
+			AcceptedStatusCodes: []int{200, 42},
+			Elements: []Element{
+				{},
+				{HtmlElement: HtmlElement{Selector: "div >"}},
+				{HtmlElement: HtmlElement{Typ: "a"}, ContentIsFollowURL: &Website{
+					Elements: []Element{
+						{HtmlElement: HtmlElement{Typ: "p", Tags: []Tag{{Typ: "id", Match: "fuzzy"}}}, All: true, Index: 2},
+					},
+				}},
+				{HtmlElement: HtmlElement{Typ: "a"}, ContentIsFollowURL: cyclic},
+				{HtmlElement: HtmlElement{XPath: "//div["}, Slice: "a:b"},
+			},
+		}
+
+		err := testWebsite.Validate()
+		var validationErr ValidationError
+		require.True(t, errors.As(err, &validationErr))
+		assert.Equal(t, ErrInvalidConfig, int(validationErr.ErrType))
+
+		var fields []string
+		for _, f := range validationErr.Fields {
+			fields = append(fields, f.Field)
+		}
+		assert.Equal(t, []string{
+			"URL",
+			"acceptedStatusCodes",
+			"Elements[0]",
+			"Elements[1]",
+			"Elements[2].followURL.Elements[0]",
+			"Elements[2].followURL.Elements[0]",
+			"Elements[3].followURL.Elements[0].followURL",
+			"Elements[4]",
+			"Elements[4]",
+		}, fields)
+	}
+
+	testMap["request"] = func(t *testing.T) {
+		testWebsite := Website{
+			URL: "https://example.com",
+			Request: &Request{
+				Method: "GET /",
+				Header: map[string]string{"Accept-Language": "{{lang}}", "Bad Name": "x", "X-Token": "a\nb"},
+			},
+			Elements: []Element{{HtmlElement: HtmlElement{Typ: "h1"}}},
+		}
+
+		var validationErr ValidationError
+		require.True(t, errors.As(testWebsite.Validate(), &validationErr))
+		var msgs []string
+		for _, f := range validationErr.Fields {
+			msgs = append(msgs, f.Error())
+		}
+		assert.Equal(t, []string{
+			`request.method: invalid method "GET /"`,
+			`request.header: invalid header field name "Bad Name"`,
+			"request.header: invalid value of header field X-Token",
+		}, msgs)
+	}
+
+	for testName, testFunc := range testMap {
+		t.Run(testName, testFunc)
+	}
+}
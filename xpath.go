@@ -0,0 +1,884 @@
+package scraper
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// XPathExpr defines the data structure for a compiled XPath expression selecting nodes
+//
+// The supported subset of XPath 1.0 consists of absolute and relative location paths, the abbreviations
+// // . .. @ and *, the axes child, descendant, descendant-or-self, self, parent, ancestor,
+// ancestor-or-self, following-sibling, preceding-sibling and attribute, the node tests
+// text(), node() and comment(), unions (|), predicates with positions, comparisons, and, or, + and -,
+// and the functions last, position, count, contains, starts-with, ends-with, normalize-space,
+// string, string-length, concat, name, not, true, false and number
+//
+// Selecting attributes (e.g. //a/@href) returns text nodes containing the values of the attributes,
+// whose parent is the element holding the attribute
+type XPathExpr struct {
+	str  string
+	expr xpathExpr
+}
+
+// CompileXPath parses expr into an XPathExpr, returning an error of type ErrInvalidXPath if expr is not valid
+func CompileXPath(expr string) (*XPathExpr, error) {
+	p := &xpathParser{str: expr}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != xpathTokEOF {
+		return nil, p.err("unexpected " + strconv.Quote(p.tok.val))
+	}
+	switch e.(type) {
+	case *xpathPath, *xpathUnion, *xpathFilter:
+	default:
+		return nil, newErr(ErrInvalidXPath, "invalid XPath "+strconv.Quote(expr)+": expression does not select nodes")
+	}
+	return &XPathExpr{str: expr, expr: e}, nil
+}
+
+// String returns the source of the expression
+func (x *XPathExpr) String() string {
+	return x.str
+}
+
+// Select returns all nodes selected by the expression, using root as the context node, in document order
+func (x *XPathExpr) Select(root *html.Node) []*html.Node {
+	nodes, _ := x.expr.eval(xpathContext{node: root, pos: 1, size: 1}).([]*html.Node)
+	return nodes
+}
+
+// xpathContext defines the context an expression is evaluated in
+type xpathContext struct {
+	node      *html.Node
+	pos, size int
+}
+
+// xpathExpr defines the interface of an expression, evaluating to []*html.Node, string, float64 or bool
+type xpathExpr interface {
+	eval(ctx xpathContext) interface{}
+}
+
+type (
+	xpathLiteral struct{ val interface{} }
+	xpathBinary  struct {
+		op   string
+		l, r xpathExpr
+	}
+	xpathNegate struct{ expr xpathExpr }
+	xpathUnion  struct{ l, r xpathExpr }
+	xpathFunc   struct {
+		name string
+		args []xpathExpr
+	}
+	// xpathPath selects steps starting at the nodes of filter, at the root if absolute, or at the context node
+	xpathPath struct {
+		filter   xpathExpr
+		absolute bool
+		steps    []xpathStep
+	}
+	// xpathFilter applies predicates to the nodes of expr
+	xpathFilter struct {
+		expr       xpathExpr
+		predicates []xpathExpr
+	}
+	xpathStep struct {
+		axis       string
+		test       xpathNodeTest
+		predicates []xpathExpr
+	}
+	// xpathNodeTest tests the name of a node or, if kind is set, its kind (text, node or comment)
+	xpathNodeTest struct {
+		kind string
+		name string
+	}
+)
+
+func (e *xpathLiteral) eval(ctx xpathContext) interface{} {
+	return e.val
+}
+
+func (e *xpathNegate) eval(ctx xpathContext) interface{} {
+	return -xpathNumber(e.expr.eval(ctx))
+}
+
+func (e *xpathUnion) eval(ctx xpathContext) interface{} {
+	l, _ := e.l.eval(ctx).([]*html.Node)
+	r, _ := e.r.eval(ctx).([]*html.Node)
+	return documentOrder(append(l, r...))
+}
+
+func (e *xpathBinary) eval(ctx xpathContext) interface{} {
+	switch e.op {
+	case "or":
+		return xpathBoolean(e.l.eval(ctx)) || xpathBoolean(e.r.eval(ctx))
+	case "and":
+		return xpathBoolean(e.l.eval(ctx)) && xpathBoolean(e.r.eval(ctx))
+	case "+":
+		return xpathNumber(e.l.eval(ctx)) + xpathNumber(e.r.eval(ctx))
+	case "-":
+		return xpathNumber(e.l.eval(ctx)) - xpathNumber(e.r.eval(ctx))
+	}
+	return xpathCompare(e.op, e.l.eval(ctx), e.r.eval(ctx))
+}
+
+func (e *xpathFilter) eval(ctx xpathContext) interface{} {
+	nodes, _ := e.expr.eval(ctx).([]*html.Node)
+	for _, pred := range e.predicates {
+		nodes = filterPredicate(nodes, pred)
+	}
+	return nodes
+}
+
+func (e *xpathPath) eval(ctx xpathContext) interface{} {
+	var nodes []*html.Node
+	switch {
+	case e.filter != nil:
+		nodes, _ = e.filter.eval(ctx).([]*html.Node)
+	case e.absolute:
+		root := ctx.node
+		for root.Parent != nil {
+			root = root.Parent
+		}
+		nodes = []*html.Node{root}
+	default:
+		nodes = []*html.Node{ctx.node}
+	}
+
+	for _, step := range e.steps {
+		var selected []*html.Node
+		for _, node := range nodes {
+			var candidates []*html.Node
+			for _, n := range xpathAxis(step.axis, node) {
+				if step.test.match(n, step.axis) {
+					candidates = append(candidates, n)
+				}
+			}
+			for _, pred := range step.predicates {
+				candidates = filterPredicate(candidates, pred)
+			}
+			selected = append(selected, candidates...)
+		}
+		nodes = documentOrder(selected)
+	}
+	return nodes
+}
+
+// filterPredicate returns the nodes for which pred is true, a number is compared to the position of a node
+func filterPredicate(nodes []*html.Node, pred xpathExpr) (filtered []*html.Node) {
+	for i, n := range nodes {
+		val := pred.eval(xpathContext{node: n, pos: i + 1, size: len(nodes)})
+		if num, ok := val.(float64); ok {
+			if num == float64(i+1) {
+				filtered = append(filtered, n)
+			}
+		} else if xpathBoolean(val) {
+			filtered = append(filtered, n)
+		}
+	}
+	return
+}
+
+// match returns whether node passes the node test on axis
+func (t xpathNodeTest) match(node *html.Node, axis string) bool {
+	switch t.kind {
+	case "node":
+		return true
+	case "text":
+		return node.Type == html.TextNode
+	case "comment":
+		return node.Type == html.CommentNode
+	}
+	if axis == "attribute" {
+		return t.name == "*" || t.name == node.Attr[0].Val
+	}
+	return node.Type == html.ElementNode && (t.name == "*" || t.name == node.Data)
+}
+
+// xpathAxis returns the nodes on axis of node, in proximity order
+func xpathAxis(axis string, node *html.Node) (nodes []*html.Node) {
+	switch axis {
+	case "self":
+		nodes = append(nodes, node)
+	case "child":
+		for c := node.FirstChild; c != nil; c = c.NextSibling {
+			nodes = append(nodes, c)
+		}
+	case "descendant-or-self":
+		nodes = append(nodes, node)
+		fallthrough
+	case "descendant":
+		for c := node.FirstChild; c != nil; c = c.NextSibling {
+			nodes = append(nodes, xpathAxis("descendant-or-self", c)...)
+		}
+	case "parent":
+		if node.Parent != nil {
+			nodes = append(nodes, node.Parent)
+		}
+	case "ancestor-or-self":
+		nodes = append(nodes, node)
+		fallthrough
+	case "ancestor":
+		for p := node.Parent; p != nil; p = p.Parent {
+			nodes = append(nodes, p)
+		}
+	case "following-sibling":
+		for s := node.NextSibling; s != nil; s = s.NextSibling {
+			nodes = append(nodes, s)
+		}
+	case "preceding-sibling":
+		for s := node.PrevSibling; s != nil; s = s.PrevSibling {
+			nodes = append(nodes, s)
+		}
+	case "attribute":
+		if node.Type == html.ElementNode {
+			for _, attr := range node.Attr {
+				nodes = append(nodes, &html.Node{
+					Type:   html.TextNode,
+					Data:   attr.Val,
+					Parent: node,
+					Attr:   []html.Attribute{{Key: xpathAttrKey, Val: attr.Key}},
+				})
+			}
+		}
+	}
+	return
+}
+
+// xpathAttrKey marks the unlinked text nodes representing attributes, the value of the mark being the attribute name
+const xpathAttrKey = "xpath-attribute"
+
+// isAttributeNode returns whether node represents an attribute selected by an XPath expression
+func isAttributeNode(node *html.Node) bool {
+	return node.Type == html.TextNode && len(node.Attr) == 1 && node.Attr[0].Key == xpathAttrKey
+}
+
+// documentOrder sorts nodes in document order, removing duplicates
+func documentOrder(nodes []*html.Node) []*html.Node {
+	if len(nodes) < 2 {
+		return nodes
+	}
+
+	root := nodes[0]
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	order := make(map[*html.Node]int)
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		order[n] = len(order)
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+
+	// attributes are ordered directly after the element holding them
+	key := func(n *html.Node) int {
+		if isAttributeNode(n) {
+			return order[n.Parent]*2 + 1
+		}
+		return order[n] * 2
+	}
+
+	seen := make(map[*html.Node]bool)
+	var unique []*html.Node
+	for _, n := range nodes {
+		if !seen[n] {
+			seen[n] = true
+			unique = append(unique, n)
+		}
+	}
+	sort.SliceStable(unique, func(i, j int) bool { return key(unique[i]) < key(unique[j]) })
+	return unique
+}
+
+func (e *xpathFunc) eval(ctx xpathContext) interface{} {
+	args := make([]interface{}, len(e.args))
+	for i, arg := range e.args {
+		args[i] = arg.eval(ctx)
+	}
+	// functions defaulting to the context node
+	if len(args) == 0 {
+		switch e.name {
+		case "string", "normalize-space", "string-length", "number", "name":
+			args = append(args, []*html.Node{ctx.node})
+		}
+	}
+
+	switch e.name {
+	case "last":
+		return float64(ctx.size)
+	case "position":
+		return float64(ctx.pos)
+	case "count":
+		nodes, _ := args[0].([]*html.Node)
+		return float64(len(nodes))
+	case "contains":
+		return strings.Contains(xpathString(args[0]), xpathString(args[1]))
+	case "starts-with":
+		return strings.HasPrefix(xpathString(args[0]), xpathString(args[1]))
+	case "ends-with":
+		return strings.HasSuffix(xpathString(args[0]), xpathString(args[1]))
+	case "normalize-space":
+		return strings.Join(strings.Fields(xpathString(args[0])), " ")
+	case "string":
+		return xpathString(args[0])
+	case "string-length":
+		return float64(len([]rune(xpathString(args[0]))))
+	case "concat":
+		var str string
+		for _, arg := range args {
+			str += xpathString(arg)
+		}
+		return str
+	case "name":
+		if nodes, _ := args[0].([]*html.Node); len(nodes) > 0 && nodes[0].Type == html.ElementNode {
+			return nodes[0].Data
+		}
+		return ""
+	case "not":
+		return !xpathBoolean(args[0])
+	case "true":
+		return true
+	case "false":
+		return false
+	case "number":
+		return xpathNumber(args[0])
+	}
+	return nil
+}
+
+// xpathFuncArgs defines the minimum and maximum number of arguments of the supported functions, -1 being unlimited
+var xpathFuncArgs = map[string][2]int{
+	"last":            {0, 0},
+	"position":        {0, 0},
+	"count":           {1, 1},
+	"contains":        {2, 2},
+	"starts-with":     {2, 2},
+	"ends-with":       {2, 2},
+	"normalize-space": {0, 1},
+	"string":          {0, 1},
+	"string-length":   {0, 1},
+	"concat":          {2, -1},
+	"name":            {0, 1},
+	"not":             {1, 1},
+	"true":            {0, 0},
+	"false":           {0, 0},
+	"number":          {0, 1},
+}
+
+// xpathStringValue returns the string-value of node
+func xpathStringValue(node *html.Node) string {
+	switch node.Type {
+	case html.TextNode, html.CommentNode:
+		return node.Data
+	}
+	return GetTextOfNode(node, false)
+}
+
+// xpathString converts val to a string
+func xpathString(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return strconv.FormatInt(int64(v), 10)
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []*html.Node:
+		if len(v) > 0 {
+			return xpathStringValue(v[0])
+		}
+	}
+	return ""
+}
+
+// xpathNumber converts val to a number
+func xpathNumber(val interface{}) float64 {
+	switch v := val.(type) {
+	case float64:
+		return v
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	}
+	num, err := strconv.ParseFloat(strings.TrimSpace(xpathString(val)), 64)
+	if err != nil {
+		return math.NaN()
+	}
+	return num
+}
+
+// xpathBoolean converts val to a boolean
+func xpathBoolean(val interface{}) bool {
+	switch v := val.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0 && !math.IsNaN(v)
+	case string:
+		return v != ""
+	case []*html.Node:
+		return len(v) > 0
+	}
+	return false
+}
+
+// xpathCompare compares l and r using op, a node-set matches if any of its nodes matches
+func xpathCompare(op string, l, r interface{}) bool {
+	if nodes, ok := l.([]*html.Node); ok {
+		if _, ok := r.(bool); ok {
+			return compareValues(op, len(nodes) > 0, r)
+		}
+		for _, n := range nodes {
+			if xpathCompare(op, xpathStringValue(n), r) {
+				return true
+			}
+		}
+		return false
+	}
+	if nodes, ok := r.([]*html.Node); ok {
+		if _, ok := l.(bool); ok {
+			return compareValues(op, l, len(nodes) > 0)
+		}
+		for _, n := range nodes {
+			if xpathCompare(op, l, xpathStringValue(n)) {
+				return true
+			}
+		}
+		return false
+	}
+	return compareValues(op, l, r)
+}
+
+// compareValues compares the primitive values l and r using op
+func compareValues(op string, l, r interface{}) bool {
+	if op == "=" || op == "!=" {
+		var equal bool
+		_, lBool := l.(bool)
+		_, rBool := r.(bool)
+		_, lNum := l.(float64)
+		_, rNum := r.(float64)
+		switch {
+		case lBool || rBool:
+			equal = xpathBoolean(l) == xpathBoolean(r)
+		case lNum || rNum:
+			equal = xpathNumber(l) == xpathNumber(r)
+		default:
+			equal = xpathString(l) == xpathString(r)
+		}
+		return equal == (op == "=")
+	}
+
+	a, b := xpathNumber(l), xpathNumber(r)
+	switch op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
+
+// xpath token kinds
+const (
+	xpathTokEOF = iota
+	xpathTokName
+	xpathTokString
+	xpathTokNumber
+	xpathTokOp
+)
+
+// xpathToken defines a token of an XPath expression
+type xpathToken struct {
+	kind int
+	val  string
+	pos  int
+}
+
+// xpathParser defines the state of parsing an XPath expression
+type xpathParser struct {
+	str string
+	pos int
+	tok xpathToken
+}
+
+// err returns an error of type ErrInvalidXPath describing the problem msg at the current token
+func (p *xpathParser) err(msg string) error {
+	return newErr(ErrInvalidXPath, "invalid XPath "+strconv.Quote(p.str)+": "+msg+" at position "+strconv.Itoa(p.tok.pos))
+}
+
+// next reads the next token into p.tok
+func (p *xpathParser) next() error {
+	for p.pos < len(p.str) && strings.IndexByte(" \t\n\r", p.str[p.pos]) >= 0 {
+		p.pos++
+	}
+	start := p.pos
+	p.tok = xpathToken{pos: start}
+	if p.pos >= len(p.str) {
+		p.tok.kind = xpathTokEOF
+		return nil
+	}
+
+	c := p.str[p.pos]
+	switch {
+	case c == '"' || c == '\'':
+		end := strings.IndexByte(p.str[p.pos+1:], c)
+		if end < 0 {
+			return p.err("unterminated string")
+		}
+		p.tok.kind, p.tok.val = xpathTokString, p.str[p.pos+1:p.pos+1+end]
+		p.pos += end + 2
+		return nil
+	case c >= '0' && c <= '9' || c == '.' && p.pos+1 < len(p.str) && p.str[p.pos+1] >= '0' && p.str[p.pos+1] <= '9':
+		for p.pos < len(p.str) && (p.str[p.pos] >= '0' && p.str[p.pos] <= '9' || p.str[p.pos] == '.') {
+			p.pos++
+		}
+		p.tok.kind = xpathTokNumber
+	case isIdentStart(c) && c != '-' && c != '\\':
+		for p.pos < len(p.str) && (isIdentStart(p.str[p.pos]) && p.str[p.pos] != '\\' || p.str[p.pos] >= '0' && p.str[p.pos] <= '9' || p.str[p.pos] == '.') {
+			p.pos++
+		}
+		p.tok.kind = xpathTokName
+	default:
+		p.tok.kind = xpathTokOp
+		for _, op := range []string{"//", "::", "..", "!=", "<=", ">=", "/", "[", "]", "(", ")", "@", ",", "|", ".", "*", "=", "<", ">", "+", "-"} {
+			if strings.HasPrefix(p.str[p.pos:], op) {
+				p.pos += len(op)
+				p.tok.val = op
+				return nil
+			}
+		}
+		return p.err("unexpected " + strconv.QuoteRune(rune(c)))
+	}
+	p.tok.val = p.str[start:p.pos]
+	return nil
+}
+
+// isOp returns whether the current token is one of the operators ops
+func (p *xpathParser) isOp(ops ...string) bool {
+	if p.tok.kind != xpathTokOp && !(p.tok.kind == xpathTokName && (p.tok.val == "and" || p.tok.val == "or")) {
+		return false
+	}
+	for _, op := range ops {
+		if p.tok.val == op {
+			return true
+		}
+	}
+	return false
+}
+
+// expect consumes the operator op, returning an error if the current token is not op
+func (p *xpathParser) expect(op string) error {
+	if !p.isOp(op) {
+		return p.err("expected " + strconv.Quote(op))
+	}
+	return p.next()
+}
+
+// parseBinary parses operands parsed by operand, joined by the operators ops
+func (p *xpathParser) parseBinary(operand func() (xpathExpr, error), ops ...string) (xpathExpr, error) {
+	l, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp(ops...) {
+		op := p.tok.val
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		r, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		l = &xpathBinary{op: op, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *xpathParser) parseOr() (xpathExpr, error) {
+	return p.parseBinary(p.parseAnd, "or")
+}
+
+func (p *xpathParser) parseAnd() (xpathExpr, error) {
+	return p.parseBinary(p.parseEquality, "and")
+}
+
+func (p *xpathParser) parseEquality() (xpathExpr, error) {
+	return p.parseBinary(p.parseRelational, "=", "!=")
+}
+
+func (p *xpathParser) parseRelational() (xpathExpr, error) {
+	return p.parseBinary(p.parseAdditive, "<", "<=", ">", ">=")
+}
+
+func (p *xpathParser) parseAdditive() (xpathExpr, error) {
+	return p.parseBinary(p.parseUnary, "+", "-")
+}
+
+func (p *xpathParser) parseUnary() (xpathExpr, error) {
+	if p.isOp("-") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &xpathNegate{expr: expr}, nil
+	}
+	return p.parseUnion()
+}
+
+func (p *xpathParser) parseUnion() (xpathExpr, error) {
+	l, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("|") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		r, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		l = &xpathUnion{l: l, r: r}
+	}
+	return l, nil
+}
+
+// parsePath parses a location path or a filter expression optionally followed by a relative location path
+func (p *xpathParser) parsePath() (xpathExpr, error) {
+	path := &xpathPath{}
+
+	switch {
+	case p.tok.kind == xpathTokString || p.tok.kind == xpathTokNumber || p.isOp("(") || p.isFuncCall():
+		primary, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		if p.isOp("[") {
+			filter := &xpathFilter{expr: primary}
+			if filter.predicates, err = p.parsePredicates(); err != nil {
+				return nil, err
+			}
+			primary = filter
+		}
+		if !p.isOp("/", "//") {
+			return primary, nil
+		}
+		path.filter = primary
+	case p.isOp("/"):
+		path.absolute = true
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if !p.startsStep() {
+			return path, nil
+		}
+	case p.isOp("//"):
+		path.absolute = true
+	}
+
+	return path, p.parseSteps(path)
+}
+
+// isFuncCall returns whether the current token starts a function call
+func (p *xpathParser) isFuncCall() bool {
+	if p.tok.kind != xpathTokName {
+		return false
+	}
+	switch p.tok.val {
+	case "node", "text", "comment":
+		return false
+	}
+	rest := strings.TrimLeft(p.str[p.pos:], " \t\n\r")
+	return strings.HasPrefix(rest, "(")
+}
+
+// startsStep returns whether the current token starts a location step
+func (p *xpathParser) startsStep() bool {
+	return p.tok.kind == xpathTokName || p.isOp("*", "@", ".", "..")
+}
+
+// parsePrimary parses a literal, a number, a parenthesized expression or a function call
+func (p *xpathParser) parsePrimary() (xpathExpr, error) {
+	tok := p.tok
+	switch {
+	case tok.kind == xpathTokString:
+		return &xpathLiteral{val: tok.val}, p.next()
+	case tok.kind == xpathTokNumber:
+		num, err := strconv.ParseFloat(tok.val, 64)
+		if err != nil {
+			return nil, p.err("invalid number " + strconv.Quote(tok.val))
+		}
+		return &xpathLiteral{val: num}, p.next()
+	case p.isOp("("):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return expr, p.expect(")")
+	}
+
+	fn := &xpathFunc{name: tok.val}
+	limits, ok := xpathFuncArgs[fn.name]
+	if !ok {
+		return nil, p.err("unsupported function " + strconv.Quote(fn.name))
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	for !p.isOp(")") {
+		if len(fn.args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		fn.args = append(fn.args, arg)
+	}
+	if len(fn.args) < limits[0] || limits[1] >= 0 && len(fn.args) > limits[1] {
+		return nil, p.err("wrong number of arguments for " + strconv.Quote(fn.name))
+	}
+	return fn, p.next()
+}
+
+// parseSteps parses location steps separated by / and //, adding them to path
+func (p *xpathParser) parseSteps(path *xpathPath) error {
+	if !p.isOp("//") && !p.startsStep() {
+		return p.err("expected location step")
+	}
+	for {
+		if p.isOp("//") {
+			path.steps = append(path.steps, xpathStep{axis: "descendant-or-self", test: xpathNodeTest{kind: "node"}})
+			if err := p.next(); err != nil {
+				return err
+			}
+		}
+
+		step, err := p.parseStep()
+		if err != nil {
+			return err
+		}
+		path.steps = append(path.steps, step)
+
+		if p.isOp("/") {
+			if err := p.next(); err != nil {
+				return err
+			}
+		} else if !p.isOp("//") {
+			return nil
+		}
+	}
+}
+
+// xpathAxes lists the supported axes
+var xpathAxes = map[string]bool{
+	"child": true, "descendant": true, "descendant-or-self": true, "self": true, "parent": true, "ancestor": true,
+	"ancestor-or-self": true, "following-sibling": true, "preceding-sibling": true, "attribute": true,
+}
+
+// parseStep parses a single location step including its predicates
+func (p *xpathParser) parseStep() (step xpathStep, err error) {
+	step.axis = "child"
+	switch {
+	case p.isOp("."):
+		return xpathStep{axis: "self", test: xpathNodeTest{kind: "node"}}, p.next()
+	case p.isOp(".."):
+		return xpathStep{axis: "parent", test: xpathNodeTest{kind: "node"}}, p.next()
+	case p.isOp("@"):
+		step.axis = "attribute"
+		if err = p.next(); err != nil {
+			return
+		}
+	case p.tok.kind == xpathTokName && strings.HasPrefix(p.str[p.pos:], "::"):
+		if !xpathAxes[p.tok.val] {
+			return step, p.err("unsupported axis " + strconv.Quote(p.tok.val))
+		}
+		step.axis = p.tok.val
+		if err = p.next(); err != nil {
+			return
+		}
+		if err = p.expect("::"); err != nil {
+			return
+		}
+	}
+
+	switch {
+	case p.isOp("*"):
+		step.test.name = "*"
+	case p.tok.kind == xpathTokName:
+		step.test.name = strings.ToLower(p.tok.val)
+		switch p.tok.val {
+		case "node", "text", "comment":
+			if strings.HasPrefix(strings.TrimLeft(p.str[p.pos:], " \t\n\r"), "(") {
+				step.test = xpathNodeTest{kind: p.tok.val}
+				if err = p.next(); err != nil {
+					return
+				}
+				if err = p.expect("("); err != nil {
+					return
+				}
+				if !p.isOp(")") {
+					return step, p.err("expected \")\"")
+				}
+			}
+		}
+	default:
+		return step, p.err("expected node test")
+	}
+	if err = p.next(); err != nil {
+		return
+	}
+
+	step.predicates, err = p.parsePredicates()
+	return
+}
+
+// parsePredicates parses any number of predicates
+func (p *xpathParser) parsePredicates() (predicates []xpathExpr, err error) {
+	for p.isOp("[") {
+		if err = p.next(); err != nil {
+			return
+		}
+		var pred xpathExpr
+		if pred, err = p.parseOr(); err != nil {
+			return
+		}
+		if err = p.expect("]"); err != nil {
+			return
+		}
+		predicates = append(predicates, pred)
+	}
+	return
+}
@@ -0,0 +1,127 @@
+package scraper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestXPath(t *testing.T) {
+	documentNode, err := GetHTMLNode(testSelectorHTML)
+	require.NoError(t, err)
+
+	selectText := func(t *testing.T, expr string) []string {
+		x, err := CompileXPath(expr)
+		require.NoError(t, err)
+		var texts []string
+		for _, node := range x.Select(documentNode) {
+			texts = append(texts, GetTextOfNode(node, false))
+		}
+		return texts
+	}
+
+	testCases := map[string][]string{
+		"//h2":                                            {"First product", "Second product"},
+		"/html/body/section/p":                            {"Outside"},
+		"//div[@id='second']/h2":                          {"Second product"},
+		"//div[2]/span":                                   {"20"},
+		"//div/span[1]":                                   {"10", "20"},
+		"//div/span[last()]":                              {"8", "20"},
+		"//div[1]/*[last()-1]":                            {"8"},
+		"(//span)[2]":                                     {"8"},
+		"(//span)[position() > 1]":                        {"8", "20"},
+		"//span[contains(@class, 'sale')]":                {"8"},
+		"//a[starts-with(@href, 'https')]":                {"Details"},
+		"//a[ends-with(@href, '.pdf')]/@href":             {"/second.pdf"},
+		"//h2[contains(text(), 'Second')]":                {"Second product"},
+		"//h2[text()='First product']/../@id":             {"first"},
+		"//span[.='20']/parent::div/h2":                   {"Second product"},
+		"//h2/following-sibling::span":                    {"10", "8", "20"},
+		"//a/preceding-sibling::*[1]":                     {"8", ""},
+		"//span[@class='sale']":                           nil,
+		"//span[@class='price' and . > 15]":               {"20"},
+		"//span[. = '8' or . = '10']":                     {"10", "8"},
+		"//div[not(@class='product featured')]/h2":        {"Second product"},
+		"//h2 | //section/p":                              {"First product", "Second product", "Outside"},
+		"//a/@href":                                       {"https://example.com/first", "/second.pdf"},
+		"//a/@*":                                          {"https://example.com/first", "en-US", "/second.pdf", "2"},
+		"//div[count(span) = 2]/h2":                       {"First product"},
+		"//section/descendant::text()[normalize-space()]": {"Outside"},
+		"//p[not(node())]":                                {""},
+		"//span/ancestor::div[@id]/@id":                   {"first", "second"},
+		"//div/self::div[@id='first']/child::h2":          {"First product"},
+		"//*[@data-id=2]":                                 {"Download"},
+	}
+
+	for expr, expected := range testCases {
+		expr, expected := expr, expected
+		t.Run(expr, func(t *testing.T) {
+			assert.Equal(t, expected, selectText(t, expr))
+		})
+	}
+}
+
+func TestCompileXPathErrors(t *testing.T) {
+	testCases := map[string]string{
+		"":                   `invalid XPath "": expected location step at position 0`,
+		"//div[":             `invalid XPath "//div[": expected location step at position 6`,
+		"//div[1":            `invalid XPath "//div[1": expected "]" at position 7`,
+		"//div[@id='x]":      `invalid XPath "//div[@id='x]": unterminated string at position 10`,
+		"//div/sibling::p":   `invalid XPath "//div/sibling::p": unsupported axis "sibling" at position 6`,
+		"//div[foo()]":       `invalid XPath "//div[foo()]": unsupported function "foo" at position 6`,
+		"//div[contains(.)]": `invalid XPath "//div[contains(.)]": wrong number of arguments for "contains" at position 16`,
+		"count(//div)":       `invalid XPath "count(//div)": expression does not select nodes`,
+		"//div#":             `invalid XPath "//div#": unexpected '#' at position 5`,
+	}
+
+	for expr, expected := range testCases {
+		_, err := CompileXPath(expr)
+		require.Error(t, err, expr)
+		assert.Equal(t, expected, err.Error())
+		assert.Equal(t, ErrInvalidXPath, int(err.(Error).ErrType))
+	}
+}
+
+func TestGetElementNodesXPath(t *testing.T) {
+	documentNode, err := GetHTMLNode(testSelectorHTML)
+	require.NoError(t, err)
+
+	testMap := make(map[string]func(t *testing.T), 0)
+
+	testMap["xpath"] = func(t *testing.T) {
+		testElement := Element{
+			HtmlElement: HtmlElement{
+				XPath: "//div[@class='product']/span[1]",
+			},
+			Settings: Settings{
+				FormatSettings: FormatSettings{
+					AddAfter: " USD",
+				},
+			},
+		}
+		content, err := testElement.ScrapeTreeForElement(documentNode)
+		require.NoError(t, err)
+		assert.Equal(t, "20 USD", content)
+	}
+	testMap["missingElement"] = func(t *testing.T) {
+		testElement := HtmlElement{
+			XPath: "//table",
+		}
+		_, err := testElement.GetElementNodes(documentNode)
+		require.Error(t, err)
+		assert.Equal(t, "missing //table in the node tree", err.Error())
+	}
+	testMap["invalidXPath"] = func(t *testing.T) {
+		testElement := HtmlElement{
+			XPath: "//div[",
+		}
+		_, err := testElement.GetElementNodes(documentNode)
+		require.Error(t, err)
+		assert.Equal(t, ErrInvalidXPath, int(err.(Error).ErrType))
+	}
+
+	for testName, testFunc := range testMap {
+		t.Run(testName, testFunc)
+	}
+}